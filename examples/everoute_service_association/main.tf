@@ -0,0 +1,42 @@
+terraform {
+  # everoute provider is recommaned to used with cloudtower provider
+  required_providers {
+    everoute = {
+      source = "registry.terraform.io/smartxworks/everoute"
+    }
+    cloudtower = {
+      source = "registry.terraform.io/smartxworks/cloudtower"
+    }
+  }
+}
+
+provider "everoute" {
+  cloudtower_server = var.cloudtower["server"]
+  username          = var.cloudtower["username"]
+  password          = var.cloudtower["password"]
+}
+
+provider "cloudtower" {
+  cloudtower_server = var.cloudtower["server"]
+  username          = var.cloudtower["username"]
+  password          = var.cloudtower["password"]
+}
+
+data "everoute_service" "service" {
+  name = var.service_name
+}
+
+data "cloudtower_cluster" "cluster" {
+  name = var.associated_cluster_config["name"]
+}
+
+resource "everoute_service_association" "association" {
+  service_id = data.everoute_service.service.services[0].id
+  cluster_id = data.cloudtower_cluster.cluster.clusters[0].id
+  vdses = [
+    for vdsid in var.associated_cluster_config["vdses"] :
+    {
+      id = vdsid
+    }
+  ]
+}
@@ -0,0 +1,18 @@
+variable "cloudtower" {
+  type = object({
+    server   = string
+    username = string
+    password = string
+  })
+}
+
+variable "service_name" {
+  type = string
+}
+
+variable "associated_cluster_config" {
+  type = object({
+    name  = string
+    vdses = list(string)
+  })
+}
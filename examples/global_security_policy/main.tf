@@ -67,8 +67,19 @@ resource "everoute_global_security_policy" "global_security_policy" {
   default_action = "ALLOW"
   ingress = [
     {
-      ip_block  = "10.0.0.1",
-      udp_ports = "80,443" # only allow traffic from 80 and 443 from udp protocol
+      name        = "web-probe" # kept in terraform state only, identifies the rule in warnings
+      description = "health probes of the web load balancer"
+      ip_block    = "10.0.0.1",
+      udp_ports   = "80,443" # only allow traffic from 80 and 443 from udp protocol
+    },
+    {
+      type = "SELECTOR" # allow traffic from vms carrying the label
+      selectors = [
+        {
+          key   = "role"
+          value = "ops"
+        }
+      ]
     }
   ]
   egress = [
@@ -76,5 +87,84 @@ resource "everoute_global_security_policy" "global_security_policy" {
       ip_block    = "10.0.0.2",
       tcp_enabled = false # disable tcp port
     }
+    # allow a vm whose ips may change, resolved to a rule for each ip reported by vm tools when applied
+    # {
+    #   type = "VM"
+    #   vm   = "backup-server" # vm's id or name
+    # }
   ]
-}
\ No newline at end of file
+}
+# a rule contributed by another module, kept by the policy above only if its direction is left unset there
+# resource "everoute_global_security_policy_rule" "monitoring" {
+#   service_id = everoute_service.service.id
+#   direction  = "INGRESS"
+#   rule = {
+#     ip_block  = "10.0.1.0/24"
+#     tcp_ports = "9100"
+#   }
+# }
+
+# rules composed from statements, assign ingress and egress to a global security policy
+# data "everoute_policy_document" "base" {
+#   statements = [
+#     {
+#       name       = "dns"
+#       directions = ["EGRESS"]
+#       cidrs      = ["10.0.0.53"]
+#       protocols  = ["UDP", "TCP"]
+#       udp_ports  = "53"
+#       tcp_ports  = "53"
+#     }
+#   ]
+# }
+# ingress = data.everoute_policy_document.base.ingress
+
+# regression test of the policy above, evaluated on every plan
+data "everoute_policy_evaluation" "probe" {
+  service_id     = everoute_global_security_policy.global_security_policy.service_id
+  source_ip      = "10.0.0.1"
+  destination_ip = "10.5.0.8"
+  source_managed = false # the load balancer is not managed by everoute, so only ingress rules apply
+  protocol       = "UDP"
+  port           = 443
+}
+
+check "web_probe_allowed" {
+  assert {
+    condition     = data.everoute_policy_evaluation.probe.verdict == "ALLOW"
+    error_message = "web probes are not allowed by the global security policy"
+  }
+}
+
+# guardrails of the security team
+data "everoute_policy_compliance" "guardrails" {
+  service_id = everoute_global_security_policy.global_security_policy.service_id
+  constraints = [
+    {
+      name = "no-public-remote-access"
+      forbid = {
+        direction = "INGRESS"
+        cidr      = "0.0.0.0/0"
+        protocol  = "TCP"
+        ports     = "22,3389"
+      }
+    }
+  ]
+}
+
+check "guardrails" {
+  assert {
+    condition     = data.everoute_policy_compliance.guardrails.compliant
+    error_message = join("\n", concat(
+      data.everoute_policy_compliance.guardrails.violations[*].message,
+      data.everoute_policy_compliance.guardrails.unchecked_rules[*].message, # selector rules cannot be checked by ip
+    ))
+  }
+}
+
+# an existing global security policy can be taken over by service name (terraform >= 1.5),
+# run `terraform plan -generate-config-out=generated.tf` to write its config
+# import {
+#   to = everoute_global_security_policy.existing
+#   id = "everoute-service-name"
+# }
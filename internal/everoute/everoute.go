@@ -3,6 +3,9 @@ package everoute
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/netip"
+	"sync"
 
 	"github.com/Sczlog/dgql"
 	httptransport "github.com/go-openapi/runtime/client"
@@ -15,6 +18,9 @@ type Client struct {
 	token   string
 	DgqlApi *dgql.GraphqlClient
 	Api     *apiclient.Cloudtower
+
+	locksMu      sync.Mutex
+	serviceLocks map[string]*sync.Mutex
 }
 
 func NewClient(username string, password string, server string, token string) (*Client, error) {
@@ -48,3 +54,53 @@ func NewClient(username string, password string, server string, token string) (*
 		Api:     apiclient,
 	}, nil
 }
+
+// Server returns the cloudtower server address the client connects to.
+func (c *Client) Server() string {
+	return c.server
+}
+
+// ServerAddrs resolves the cloudtower server, configured as an ip or a host name with an optional port.
+func (c *Client) ServerAddrs(ctx context.Context) ([]netip.Addr, error) {
+	host := c.server
+	if h, _, err := net.SplitHostPort(c.server); err == nil {
+		host = h
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, ip.Unmap())
+	}
+	return addrs, nil
+}
+
+// ServerPort returns the tcp port of the cloudtower server, 80 if not configured as the client connects by http.
+func (c *Client) ServerPort() string {
+	if _, port, err := net.SplitHostPort(c.server); err == nil {
+		return port
+	}
+	return "80"
+}
+
+// LockService serializes read-modify-write updates on one everoute service,
+// returns the unlock function.
+func (c *Client) LockService(serviceId string) func() {
+	c.locksMu.Lock()
+	if c.serviceLocks == nil {
+		c.serviceLocks = make(map[string]*sync.Mutex)
+	}
+	m, ok := c.serviceLocks[serviceId]
+	if !ok {
+		m = &sync.Mutex{}
+		c.serviceLocks[serviceId] = m
+	}
+	c.locksMu.Unlock()
+	m.Lock()
+	return m.Unlock
+}
@@ -0,0 +1,57 @@
+package everoute
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockServiceSerializesOneService(t *testing.T) {
+	c := &Client{}
+	unlock := c.LockService("service-1")
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		c.LockService("service-1")()
+	}()
+	select {
+	case <-locked:
+		t.Fatal("service-1 is locked twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service-1 is not locked after unlocked")
+	}
+}
+
+func TestLockServiceKeepsServicesIndependent(t *testing.T) {
+	c := &Client{}
+	unlock := c.LockService("service-1")
+	defer unlock()
+	locked := make(chan struct{})
+	go func() {
+		defer close(locked)
+		c.LockService("service-2")()
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("service-2 is blocked by the lock of service-1")
+	}
+}
+
+func TestServerPort(t *testing.T) {
+	for server, want := range map[string]string{
+		"cloudtower.local":      "80",
+		"192.168.1.10":          "80",
+		"192.168.1.10:8080":     "8080",
+		"[fd00::1]:8443":        "8443",
+		"cloudtower.local:8080": "8080",
+	} {
+		if got := (&Client{server: server}).ServerPort(); got != want {
+			t.Errorf("ServerPort() of %s = %s, want %s", server, got, want)
+		}
+	}
+}
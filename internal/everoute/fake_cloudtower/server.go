@@ -0,0 +1,134 @@
+// Package fake_cloudtower serves the cloudtower graphql and task api in memory, for tests of resources.
+package fake_cloudtower
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Sczlog/dgql"
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/tidwall/gjson"
+)
+
+// Handler serves a graphql operation, data is marshaled as the data of the response.
+type Handler func(variables gjson.Result) (data interface{}, err error)
+
+// Server answers graphql operations by handlers of operation name, api requests by handlers of api name,
+// and reports every task as succeeded.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	handlers    map[string]Handler
+	apiHandlers map[string]Handler
+	tasks       int
+}
+
+// NewServer starts a server closed when the test finishes.
+func NewServer(t *testing.T) *Server {
+	s := &Server{handlers: make(map[string]Handler), apiHandlers: make(map[string]Handler)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/", s.serveGraphql)
+	mux.HandleFunc("/v2/api/", s.serveApi)
+	mux.HandleFunc("/v2/api/get-tasks", s.serveTasks)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle registers handler of a graphql operation, replacing the former one.
+func (s *Server) Handle(operation string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[operation] = handler
+}
+
+// HandleApi registers handler of a cloudtower api like get-vms, replacing the former one.
+// handler gets the request body, and data is marshaled as the response body.
+func (s *Server) HandleApi(name string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiHandlers[name] = handler
+}
+
+// Client returns an everoute client connected to the server.
+func (s *Server) Client(t *testing.T) *everoute.Client {
+	gql, err := dgql.NewClient(s.URL + "/api/")
+	if err != nil {
+		t.Fatalf("failed to create graphql client: %s", err)
+	}
+	transport := httptransport.New(strings.TrimPrefix(s.URL, "http://"), "/v2/api", []string{"http"})
+	return &everoute.Client{
+		DgqlApi: gql,
+		Api:     apiclient.New(transport, strfmt.Default),
+	}
+}
+
+func (s *Server) serveGraphql(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request := gjson.ParseBytes(body)
+	operation := request.Get("operationName").String()
+	s.mu.Lock()
+	handler, ok := s.handlers[operation]
+	s.tasks++
+	taskId := fmt.Sprintf("task-%d", s.tasks)
+	s.mu.Unlock()
+	response := map[string]interface{}{}
+	if !ok {
+		response["errors"] = []map[string]interface{}{{"message": fmt.Sprintf("operation %s is not handled", operation)}}
+	} else if data, err := handler(request.Get("variables")); err != nil {
+		response["errors"] = []map[string]interface{}{{"message": err.Error()}}
+	} else {
+		response["data"] = data
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Task-Id", taskId)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) serveApi(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/v2/api/")
+	s.mu.Lock()
+	handler, ok := s.apiHandlers[name]
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("api %s is not handled", name), http.StatusNotFound)
+		return
+	}
+	data, err := handler(gjson.ParseBytes(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func (s *Server) serveTasks(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := gjson.GetBytes(body, "where.id").String()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode([]map[string]interface{}{{"id": id, "status": "SUCCESSED"}})
+}
@@ -0,0 +1,60 @@
+package ip_helper
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// ParseIPBlock parses a cidr or a single ip, host bits of cidr are masked,
+// a single ip is a cidr of its full length.
+func ParseIPBlock(block string) (netip.Prefix, error) {
+	block = strings.TrimSpace(block)
+	if strings.Contains(block, "/") {
+		prefix, err := netip.ParsePrefix(block)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid ip block %q, should be a cidr like 10.0.0.0/24 or an ip", block)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(block)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid ip block %q, should be a cidr like 10.0.0.0/24 or an ip", block)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// NormalizeIPBlock returns canonical form of block, a masked cidr.
+func NormalizeIPBlock(block string) (string, error) {
+	prefix, err := ParseIPBlock(block)
+	if err != nil {
+		return "", err
+	}
+	return prefix.String(), nil
+}
+
+// ContainsIPBlock reports if inner block is fully inside outer block.
+func ContainsIPBlock(outer netip.Prefix, inner netip.Prefix) bool {
+	return outer.Addr().Is4() == inner.Addr().Is4() &&
+		outer.Bits() <= inner.Bits() &&
+		outer.Contains(inner.Addr())
+}
+
+// ValidateExceptIPBlocks checks every except block is contained in block,
+// invalid blocks are skipped and left to IPBlockValidator.
+func ValidateExceptIPBlocks(block string, excepts []string) error {
+	prefix, err := ParseIPBlock(block)
+	if err != nil {
+		return nil
+	}
+	for _, e := range excepts {
+		ep, err := ParseIPBlock(e)
+		if err != nil {
+			continue
+		}
+		if !ContainsIPBlock(prefix, ep) {
+			return fmt.Errorf("except ip block %s is not contained in ip block %s", e, block)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package ip_helper
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestNormalizeIPBlock(t *testing.T) {
+	cases := []struct {
+		block   string
+		want    string
+		wantErr bool
+	}{
+		{block: "10.0.0.1", want: "10.0.0.1/32"},
+		{block: "10.0.0.1/32", want: "10.0.0.1/32"},
+		{block: "10.0.0.5/24", want: "10.0.0.0/24"},
+		{block: " 10.0.0.0/24 ", want: "10.0.0.0/24"},
+		{block: "0.0.0.0/0", want: "0.0.0.0/0"},
+		{block: "fd00::1", want: "fd00::1/128"},
+		{block: "fd00::1/64", want: "fd00::/64"},
+		{block: "10.0.0.0/33", wantErr: true},
+		{block: "10.0.0.256", wantErr: true},
+		{block: "", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.block, func(t *testing.T) {
+			got, err := NormalizeIPBlock(c.block)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("NormalizeIPBlock(%q) error = %v, want error %v", c.block, err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("NormalizeIPBlock(%q) = %q, want %q", c.block, got, c.want)
+			}
+		})
+	}
+}
+
+func TestContainsIPBlock(t *testing.T) {
+	cases := []struct {
+		outer string
+		inner string
+		want  bool
+	}{
+		{"10.0.0.0/24", "10.0.0.1/32", true},
+		{"10.0.0.0/24", "10.0.0.0/24", true},
+		{"10.0.0.0/24", "10.0.0.0/16", false},
+		{"10.0.0.0/24", "10.0.1.0/28", false},
+		{"0.0.0.0/0", "fd00::/64", false},
+	}
+	for _, c := range cases {
+		t.Run(c.outer+" "+c.inner, func(t *testing.T) {
+			if got := ContainsIPBlock(netip.MustParsePrefix(c.outer), netip.MustParsePrefix(c.inner)); got != c.want {
+				t.Errorf("ContainsIPBlock(%s, %s) = %v, want %v", c.outer, c.inner, got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateExceptIPBlocks(t *testing.T) {
+	cases := []struct {
+		name    string
+		block   string
+		excepts []string
+		wantErr bool
+	}{
+		{"inside", "10.0.0.0/24", []string{"10.0.0.0/28", "10.0.0.200"}, false},
+		{"same block", "10.0.0.0/24", []string{"10.0.0.5/24"}, false},
+		{"outside", "10.0.0.0/24", []string{"10.0.0.0/28", "10.0.1.1"}, true},
+		{"wider", "10.0.0.0/24", []string{"10.0.0.0/16"}, true},
+		{"invalid except left to validator", "10.0.0.0/24", []string{"invalid"}, false},
+		{"invalid block left to validator", "invalid", []string{"10.0.1.1"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := ValidateExceptIPBlocks(c.block, c.excepts); (err != nil) != c.wantErr {
+				t.Errorf("ValidateExceptIPBlocks(%q, %v) error = %v, want error %v", c.block, c.excepts, err, c.wantErr)
+			}
+		})
+	}
+}
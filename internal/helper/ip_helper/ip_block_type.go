@@ -0,0 +1,117 @@
+package ip_helper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var _ basetypes.StringTypable = IPBlockType{}
+
+// IPBlockType is a string type holding a cidr or an ip, blocks of the same cidr are semantically equal.
+type IPBlockType struct {
+	basetypes.StringType
+}
+
+func (t IPBlockType) String() string {
+	return "ip_helper.IPBlockType"
+}
+
+func (t IPBlockType) Equal(o attr.Type) bool {
+	other, ok := o.(IPBlockType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t IPBlockType) ValueType(ctx context.Context) attr.Value {
+	return IPBlock{}
+}
+
+func (t IPBlockType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return IPBlock{StringValue: in}, nil
+}
+
+func (t IPBlockType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+	return stringValuable, nil
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = IPBlock{}
+
+// IPBlock is a value of IPBlockType.
+type IPBlock struct {
+	basetypes.StringValue
+}
+
+func NewIPBlockValue(value string) IPBlock {
+	return IPBlock{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewIPBlockNull() IPBlock {
+	return IPBlock{StringValue: basetypes.NewStringNull()}
+}
+
+func NewIPBlockUnknown() IPBlock {
+	return IPBlock{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v IPBlock) Type(ctx context.Context) attr.Type {
+	return IPBlockType{}
+}
+
+func (v IPBlock) Equal(o attr.Value) bool {
+	other, ok := o.(IPBlock)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals treats blocks as equal if they normalize to the same cidr, like 10.0.0.1 and 10.0.0.1/32.
+func (v IPBlock) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(IPBlock)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+	prior, err := NormalizeIPBlock(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	current, err := NormalizeIPBlock(newValue.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	return prior == current, diags
+}
+
+// IPBlockListValue converts blocks to a list of IPBlockType.
+func IPBlockListValue(blocks []string) types.List {
+	values := make([]attr.Value, len(blocks))
+	for i, b := range blocks {
+		values[i] = NewIPBlockValue(b)
+	}
+	return types.ListValueMust(IPBlockType{}, values)
+}
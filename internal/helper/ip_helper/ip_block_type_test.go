@@ -0,0 +1,36 @@
+package ip_helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIPBlockStringSemanticEquals(t *testing.T) {
+	cases := []struct {
+		prior   string
+		current string
+		want    bool
+	}{
+		{"10.0.0.1", "10.0.0.1/32", true},
+		{"10.0.0.5/24", "10.0.0.0/24", true},
+		{"10.0.0.0/24", "10.0.0.0/25", false},
+		{"10.0.0.1", "10.0.0.2", false},
+		{"invalid", "invalid", false},
+	}
+	for _, c := range cases {
+		t.Run(c.prior+" "+c.current, func(t *testing.T) {
+			equal, diags := NewIPBlockValue(c.prior).StringSemanticEquals(context.Background(), NewIPBlockValue(c.current))
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if equal != c.want {
+				t.Errorf("%q equals %q = %v, want %v", c.prior, c.current, equal, c.want)
+			}
+		})
+	}
+	if _, diags := NewIPBlockValue("10.0.0.1").StringSemanticEquals(context.Background(), types.StringValue("10.0.0.1")); !diags.HasError() {
+		t.Error("comparing to a plain string is not reported")
+	}
+}
@@ -0,0 +1,32 @@
+package ip_helper
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var _ validator.String = IPBlockValidator{}
+
+func GetIPBlockValidator() validator.String {
+	return IPBlockValidator{}
+}
+
+type IPBlockValidator struct{}
+
+func (v IPBlockValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v IPBlockValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate ip block is a cidr or an ip"
+}
+
+func (v IPBlockValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := ParseIPBlock(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ip block", err.Error())
+	}
+}
@@ -1,10 +1,16 @@
 package label_helper
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	dschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/label"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
 	"github.com/tidwall/gjson"
 )
@@ -75,3 +81,42 @@ func ReadGJsonLabelToModel(input *gjson.Result, output *LabelModel) {
 	output.Key = types.StringValue(input.Get("key").String())
 	output.Value = types.StringValue(input.Get("value").String())
 }
+
+func LabelSelectorSchema() rschema.NestedAttributeObject {
+	return rschema.NestedAttributeObject{
+		Attributes: LabelResourceAttrs(),
+		Validators: []validator.Object{
+			GetLabelSelectorValidator(),
+		},
+	}
+}
+
+// ResolveLabelIds returns ids of given labels, label without id is looked up by its key and value.
+func ResolveLabelIds(api *apiclient.Cloudtower, labels []LabelModel) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	ids := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if !l.Id.IsNull() && !l.Id.IsUnknown() {
+			ids = append(ids, l.Id.ValueString())
+			continue
+		}
+		glp := label.NewGetLabelsParams()
+		glp.RequestBody = &models.GetLabelsRequestBody{
+			Where: &models.LabelWhereInput{
+				Key:   l.Key.ValueStringPointer(),
+				Value: l.Value.ValueStringPointer(),
+			},
+		}
+		labels, err := api.Label.GetLabels(glp)
+		if err != nil {
+			diags.AddError("Failed to get label", fmt.Sprintf("Unable to get label %s=%s, got error: %s", l.Key.ValueString(), l.Value.ValueString(), err))
+			continue
+		}
+		if len(labels.Payload) == 0 {
+			diags.AddError("Failed to get label", fmt.Sprintf("Label %s=%s not exist", l.Key.ValueString(), l.Value.ValueString()))
+			continue
+		}
+		ids = append(ids, *labels.Payload[0].ID)
+	}
+	return ids, diags
+}
@@ -1 +1,45 @@
 package label_helper
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ validator.Object = LabelSelectorValidator{}
+
+func GetLabelSelectorValidator() validator.Object {
+	return LabelSelectorValidator{}
+}
+
+type LabelSelectorValidator struct{}
+
+func (v LabelSelectorValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v LabelSelectorValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate label selector, make sure label is identified by id or by both key and value"
+}
+
+func (v LabelSelectorValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	attrs := req.ConfigValue.Attributes()
+	id, _ := attrs["id"].(types.String)
+	key, _ := attrs["key"].(types.String)
+	value, _ := attrs["value"].(types.String)
+	if id.IsUnknown() || key.IsUnknown() || value.IsUnknown() {
+		// temporary ignore unknown value
+		return
+	}
+	if id.IsNull() && (key.IsNull() || value.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"invalid label selector",
+			"label selector must be identified by id, or by both key and value",
+		)
+	}
+}
@@ -0,0 +1,224 @@
+package network_policy_helper
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+type NetworkPolicyRuleModel struct {
+	IPBlock       ip_helper.IPBlock    `tfsdk:"ip_block"`
+	ExceptIPBlock types.List           `tfsdk:"except_ip_block"`
+	TCPEnabled    types.Bool           `tfsdk:"tcp_enabled"`
+	TCPPorts      port_helper.PortSpec `tfsdk:"tcp_ports"`
+	UDPEnabled    types.Bool           `tfsdk:"udp_enabled"`
+	UDPPorts      port_helper.PortSpec `tfsdk:"udp_ports"`
+	ICMPEnabled   types.Bool           `tfsdk:"icmp_enabled"`
+	ServiceIds    types.List           `tfsdk:"network_service_ids"`
+	Ports         types.List           `tfsdk:"ports"`
+}
+
+func NetworkPolicyRuleSchema() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: NetworkPolicyRuleAttributes(),
+		Validators: []validator.Object{
+			&NetworkRulePolicyValidator{},
+		},
+		PlanModifiers: []planmodifier.Object{
+			rulePortsModifier{},
+		},
+	}
+}
+
+// NetworkPolicyRuleAttributes returns attributes of an ip block rule, can be extended by other rule schemas.
+func NetworkPolicyRuleAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"ip_block": schema.StringAttribute{
+			MarkdownDescription: "network policy rule included ip block, a cidr or an ip",
+			CustomType:          ip_helper.IPBlockType{},
+			Required:            true,
+			Validators: []validator.String{
+				ip_helper.GetIPBlockValidator(),
+			},
+		},
+		"except_ip_block": schema.ListAttribute{
+			MarkdownDescription: "network policy rule excluded ip block, must be contained in ip_block",
+			ElementType:         ip_helper.IPBlockType{},
+			Default: listdefault.StaticValue(
+				types.ListValueMust(ip_helper.IPBlockType{}, []attr.Value{}),
+			),
+			Optional: true,
+			Computed: true,
+			Validators: []validator.List{
+				listvalidator.UniqueValues(),
+				listvalidator.ValueStringsAre(ip_helper.GetIPBlockValidator()),
+			},
+		},
+		"tcp_enabled": schema.BoolAttribute{
+			MarkdownDescription: "if network policy is enabled for tcp protocol",
+			Default:             booldefault.StaticBool(true),
+			Optional:            true,
+			Computed:            true,
+		},
+		"tcp_ports": schema.StringAttribute{
+			MarkdownDescription: "network policy rule's tcp ports and port ranges like 80,8000-8080, empty for all ports",
+			CustomType:          port_helper.PortSpecType{},
+			Default:             stringdefault.StaticString(""),
+			Optional:            true,
+			Computed:            true,
+			Validators: []validator.String{
+				port_helper.GetPortSpecValidator(),
+			},
+		},
+		"udp_enabled": schema.BoolAttribute{
+			MarkdownDescription: "if network policy is enabled for udp protocol",
+			Default:             booldefault.StaticBool(true),
+			Optional:            true,
+			Computed:            true,
+		},
+		"udp_ports": schema.StringAttribute{
+			MarkdownDescription: "network policy rule's udp ports and port ranges like 80,8000-8080, empty for all ports",
+			CustomType:          port_helper.PortSpecType{},
+			Default:             stringdefault.StaticString(""),
+			Optional:            true,
+			Computed:            true,
+			Validators: []validator.String{
+				port_helper.GetPortSpecValidator(),
+			},
+		},
+		"icmp_enabled": schema.BoolAttribute{
+			MarkdownDescription: "if network policy is enabled for icmp protocol",
+			Default:             booldefault.StaticBool(true),
+			Optional:            true,
+			Computed:            true,
+		},
+		"ports": portsSchema(),
+		"network_service_ids": schema.ListAttribute{
+			MarkdownDescription: "ids of everoute_network_service the rule allows in addition to the protocols above",
+			ElementType:         types.StringType,
+			Default: listdefault.StaticValue(
+				types.ListValueMust(types.StringType, []attr.Value{}),
+			),
+			Optional: true,
+			Computed: true,
+			Validators: []validator.List{
+				listvalidator.UniqueValues(),
+			},
+		},
+	}
+}
+
+func ReadGqlResultToNetworkPolicyRule(input *gjson.Result) []NetworkPolicyRuleModel {
+	rules := input.Array()
+	var result = make([]NetworkPolicyRuleModel, len(rules))
+	for idx, rule := range rules {
+		result[idx] = ReadGqlResultToNetworkPolicyRuleModel(&rule)
+	}
+	return result
+}
+
+func ReadGqlResultToNetworkPolicyRuleModel(rule *gjson.Result) NetworkPolicyRuleModel {
+	var result NetworkPolicyRuleModel
+	result.IPBlock = ip_helper.NewIPBlockValue(rule.Get("ip_block").String())
+	eipb := make([]string, 0)
+	for _, v := range rule.Get("except_ip_block").Array() {
+		eipb = append(eipb, v.String())
+	}
+	result.ExceptIPBlock = ip_helper.IPBlockListValue(eipb)
+	jservices := rule.Get("services").Array()
+	services := make([]attr.Value, len(jservices))
+	for i, v := range jservices {
+		services[i] = types.StringValue(v.String())
+	}
+	result.ServiceIds, _ = types.ListValue(types.StringType, services)
+	jports := rule.Get("ports")
+	entries := readGqlResultToPortEntries(&jports)
+	// rules are read in one form like config generated on import, tcp/udp/icmp form merging entries
+	// of the same protocol if it allows the same traffic, ports entries otherwise
+	p := normalizePortEntries(entries, len(services) > 0)
+	if p.Others != "" || (len(entries) == 0 && len(services) == 0) {
+		result.Ports = portEntriesValue(entries)
+		result.TCPEnabled = types.BoolNull()
+		result.TCPPorts = port_helper.NewPortSpecNull()
+		result.UDPEnabled = types.BoolNull()
+		result.UDPPorts = port_helper.NewPortSpecNull()
+		result.ICMPEnabled = types.BoolNull()
+		return result
+	}
+	result.Ports = types.ListNull(types.ObjectType{AttrTypes: PortEntryAttrTypes()})
+	result.TCPEnabled = types.BoolValue(p.TCPEnabled)
+	result.TCPPorts = port_helper.NewPortSpecValue(p.TCPPorts)
+	result.UDPEnabled = types.BoolValue(p.UDPEnabled)
+	result.UDPPorts = port_helper.NewPortSpecValue(p.UDPPorts)
+	result.ICMPEnabled = types.BoolValue(p.ICMPEnabled)
+	return result
+}
+
+// BuildNetworkPolicyRulePorts converts rule's protocol configuration to everoute ports input.
+func BuildNetworkPolicyRulePorts(ctx context.Context, rule *NetworkPolicyRuleModel) ([]map[string]interface{}, diag.Diagnostics) {
+	entries, diags := rulePortEntries(ctx, rule)
+	ports := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		port := map[string]interface{}{
+			"protocol": e.Protocol.ValueString(),
+		}
+		switch e.Protocol.ValueString() {
+		case ProtocolTCP, ProtocolUDP:
+			spec, err := port_helper.NormalizePortSpec(e.Port.ValueString())
+			if err != nil {
+				diags.AddError("invalid ports", err.Error())
+			}
+			port["port"] = spec
+		case ProtocolICMP:
+			if !e.ICMPType.IsNull() {
+				port["icmp_type"] = e.ICMPType.ValueInt64()
+			}
+			if !e.ICMPCode.IsNull() {
+				port["icmp_code"] = e.ICMPCode.ValueInt64()
+			}
+		case ProtocolALG:
+			port["alg_protocol"] = e.AlgProtocol.ValueString()
+		}
+		ports = append(ports, port)
+	}
+	return ports, diags
+}
+
+// BuildNetworkPolicyRuleServices returns ids of network services referenced by rule.
+func BuildNetworkPolicyRuleServices(ctx context.Context, rule *NetworkPolicyRuleModel) ([]string, diag.Diagnostics) {
+	services := make([]string, 0)
+	if rule.ServiceIds.IsNull() || rule.ServiceIds.IsUnknown() {
+		return services, nil
+	}
+	diags := rule.ServiceIds.ElementsAs(ctx, &services, false)
+	return services, diags
+}
+
+// BuildNetworkPolicyRuleInput converts rule to everoute ip block rule input.
+func BuildNetworkPolicyRuleInput(ctx context.Context, rule *NetworkPolicyRuleModel) (map[string]interface{}, diag.Diagnostics) {
+	eips := make([]string, 0)
+	diags := rule.ExceptIPBlock.ElementsAs(ctx, &eips, false)
+	services, d := BuildNetworkPolicyRuleServices(ctx, rule)
+	diags.Append(d...)
+	ports, d := BuildNetworkPolicyRulePorts(ctx, rule)
+	diags.Append(d...)
+	return map[string]interface{}{
+		"type":            "IP_BLOCK",
+		"ip_block":        rule.IPBlock.ValueString(),
+		"ports":           ports,
+		"except_ip_block": eips,
+		"services":        services,
+	}, diags
+}
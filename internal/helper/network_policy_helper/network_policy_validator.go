@@ -0,0 +1,109 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+)
+
+var _ validator.Object = &NetworkRulePolicyValidator{}
+
+type NetworkRulePolicyValidator struct {
+}
+
+func (v *NetworkRulePolicyValidator) Description(context.Context) string {
+	return "validate network policy rule"
+}
+
+func (v *NetworkRulePolicyValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+func (v *NetworkRulePolicyValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, res *validator.ObjectResponse) {
+	attrs := req.ConfigValue.Attributes()
+	validateExceptIPBlocks(ctx, req, res)
+	if ports, ok := attrs["ports"].(types.List); ok && !ports.IsNull() {
+		// protocols are configured by ports entries
+		for _, f := range []string{"tcp_enabled", "tcp_ports", "udp_enabled", "udp_ports", "icmp_enabled"} {
+			if !attrs[f].IsNull() {
+				res.Diagnostics.AddError(
+					"Failed to validate network policy rule",
+					fmt.Sprintf("%s is not allowed when ports is configured", f),
+				)
+			}
+		}
+		return
+	}
+	ate := attrs["tcp_enabled"].(types.Bool)
+	aue := attrs["udp_enabled"].(types.Bool)
+	aie := attrs["icmp_enabled"].(types.Bool)
+	te := true
+	ue := true
+	ie := true
+	if !ate.IsNull() {
+		te = ate.ValueBool()
+	}
+	if !aue.IsNull() {
+		ue = aue.ValueBool()
+	}
+	if !aie.IsNull() {
+		ie = aie.ValueBool()
+	}
+	hasServices := false
+	if as, ok := attrs["network_service_ids"].(types.List); ok {
+		hasServices = as.IsUnknown() || (!as.IsNull() && len(as.Elements()) > 0)
+	}
+	if !te && !ue && !ie && !hasServices {
+		res.Diagnostics.AddError(
+			"Failed to validate network policy rule",
+			"at least one protocol should be enabled or network service referenced, otherwise remove this rule from ingress or egress",
+		)
+	}
+	if !te {
+		tp := attrs["tcp_ports"].(port_helper.PortSpec)
+		if !tp.IsNull() && tp.ValueString() != "" {
+			res.Diagnostics.AddError(
+				"Failed to validate network policy rule",
+				"if tcp protocol is disabled, tcp ports must be empty",
+			)
+		}
+	}
+	if !ue {
+		up := attrs["udp_ports"].(port_helper.PortSpec)
+		if !up.IsNull() && up.ValueString() != "" {
+			res.Diagnostics.AddError(
+				"Failed to validate network policy rule",
+				"if udp protocol is disabled, udp ports must be empty",
+			)
+		}
+	}
+}
+
+// validateExceptIPBlocks makes sure every except ip block is contained in ip block.
+func validateExceptIPBlocks(ctx context.Context, req validator.ObjectRequest, res *validator.ObjectResponse) {
+	attrs := req.ConfigValue.Attributes()
+	block, _ := attrs["ip_block"].(ip_helper.IPBlock)
+	excepts, _ := attrs["except_ip_block"].(types.List)
+	if block.IsNull() || block.IsUnknown() || excepts.IsNull() || excepts.IsUnknown() {
+		return
+	}
+	eips := make([]string, 0)
+	for _, e := range excepts.Elements() {
+		eip, ok := e.(ip_helper.IPBlock)
+		if !ok || eip.IsUnknown() {
+			// temporary ignore unknown value
+			return
+		}
+		eips = append(eips, eip.ValueString())
+	}
+	if err := ip_helper.ValidateExceptIPBlocks(block.ValueString(), eips); err != nil {
+		res.Diagnostics.AddAttributeError(
+			req.Path.AtName("except_ip_block"),
+			"Failed to validate network policy rule",
+			err.Error(),
+		)
+	}
+}
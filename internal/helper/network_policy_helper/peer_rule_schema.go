@@ -0,0 +1,322 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/label_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	PeerTypeIPBlock       = "IP_BLOCK"
+	PeerTypeSelector      = "SELECTOR"
+	PeerTypeSecurityGroup = "SECURITY_GROUP"
+	PeerTypeVM            = "VM"
+)
+
+// PeerRuleModel extends NetworkPolicyRuleModel with selector, security group and vm peers.
+type PeerRuleModel struct {
+	Type            types.String              `tfsdk:"type"`
+	IPBlock         ip_helper.IPBlock         `tfsdk:"ip_block"`
+	ExceptIPBlock   types.List                `tfsdk:"except_ip_block"`
+	Selectors       []label_helper.LabelModel `tfsdk:"selectors"`
+	SecurityGroupId types.String              `tfsdk:"security_group_id"`
+	Vm              types.String              `tfsdk:"vm"`
+	TCPEnabled      types.Bool                `tfsdk:"tcp_enabled"`
+	TCPPorts        port_helper.PortSpec      `tfsdk:"tcp_ports"`
+	UDPEnabled      types.Bool                `tfsdk:"udp_enabled"`
+	UDPPorts        port_helper.PortSpec      `tfsdk:"udp_ports"`
+	ICMPEnabled     types.Bool                `tfsdk:"icmp_enabled"`
+	ServiceIds      types.List                `tfsdk:"network_service_ids"`
+	Ports           types.List                `tfsdk:"ports"`
+	Name            types.String              `tfsdk:"name"`
+	Description     types.String              `tfsdk:"description"`
+}
+
+// PeerRuleSchema returns schema of a rule whose peer is selected by type.
+func PeerRuleSchema() schema.NestedAttributeObject {
+	attrs := NetworkPolicyRuleAttributes()
+	attrs["type"] = schema.StringAttribute{
+		MarkdownDescription: "network policy rule's peer type, valid value: IP_BLOCK, SELECTOR, SECURITY_GROUP, VM",
+		Default:             stringdefault.StaticString(PeerTypeIPBlock),
+		Optional:            true,
+		Computed:            true,
+		Validators: []validator.String{
+			stringvalidator.OneOf(PeerTypeIPBlock, PeerTypeSelector, PeerTypeSecurityGroup, PeerTypeVM),
+		},
+	}
+	attrs["ip_block"] = schema.StringAttribute{
+		MarkdownDescription: "network policy rule included ip block, a cidr or an ip, required when type is IP_BLOCK",
+		CustomType:          ip_helper.IPBlockType{},
+		Optional:            true,
+		Validators: []validator.String{
+			ip_helper.GetIPBlockValidator(),
+		},
+	}
+	attrs["selectors"] = schema.ListNestedAttribute{
+		MarkdownDescription: "labels selecting peer vms, required when type is SELECTOR",
+		Optional:            true,
+		NestedObject:        label_helper.LabelSelectorSchema(),
+	}
+	attrs["security_group_id"] = schema.StringAttribute{
+		MarkdownDescription: "peer security group's id, required when type is SECURITY_GROUP",
+		Optional:            true,
+	}
+	// everoute has no vm peer, the vm is resolved to a rule for each of its ips when applied
+	attrs["vm"] = schema.StringAttribute{
+		MarkdownDescription: "peer vm's id or name, required when type is VM. The vm's ips reported by vm tools are resolved to " +
+			"an ip block rule each when applied, changed ips are detected as drift when refreshed. " +
+			"Not supported by everoute_global_security_policy_rule",
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+	// rules have no name in everoute, name and description are kept in terraform state only
+	attrs["name"] = schema.StringAttribute{
+		MarkdownDescription: "network policy rule's name, unique among rules of the same direction, kept in terraform state only " +
+			"and used to identify the rule in warnings",
+		Optional: true,
+		Validators: []validator.String{
+			stringvalidator.LengthAtLeast(1),
+		},
+	}
+	attrs["description"] = schema.StringAttribute{
+		MarkdownDescription: "network policy rule's description, kept in terraform state only",
+		Optional:            true,
+	}
+	return schema.NestedAttributeObject{
+		Attributes: attrs,
+		Validators: []validator.Object{
+			&NetworkRulePolicyValidator{},
+			GetPeerRuleValidator(),
+		},
+		PlanModifiers: []planmodifier.Object{
+			rulePortsModifier{},
+		},
+	}
+}
+
+func (p *PeerRuleModel) NetworkPolicyRule() NetworkPolicyRuleModel {
+	return NetworkPolicyRuleModel{
+		IPBlock:       p.IPBlock,
+		ExceptIPBlock: p.ExceptIPBlock,
+		TCPEnabled:    p.TCPEnabled,
+		TCPPorts:      p.TCPPorts,
+		UDPEnabled:    p.UDPEnabled,
+		UDPPorts:      p.UDPPorts,
+		ICMPEnabled:   p.ICMPEnabled,
+		ServiceIds:    p.ServiceIds,
+		Ports:         p.Ports,
+	}
+}
+
+// Equal reports if rules hold equal values attribute by attribute, as elements of a set are compared.
+func (p *PeerRuleModel) Equal(o *PeerRuleModel) bool {
+	if len(p.Selectors) != len(o.Selectors) {
+		return false
+	}
+	for i := range p.Selectors {
+		if !p.Selectors[i].Id.Equal(o.Selectors[i].Id) || !p.Selectors[i].Key.Equal(o.Selectors[i].Key) ||
+			!p.Selectors[i].Value.Equal(o.Selectors[i].Value) {
+			return false
+		}
+	}
+	return p.Type.Equal(o.Type) && p.IPBlock.Equal(o.IPBlock) && p.ExceptIPBlock.Equal(o.ExceptIPBlock) &&
+		p.SecurityGroupId.Equal(o.SecurityGroupId) && p.Vm.Equal(o.Vm) &&
+		p.TCPEnabled.Equal(o.TCPEnabled) && p.TCPPorts.Equal(o.TCPPorts) &&
+		p.UDPEnabled.Equal(o.UDPEnabled) && p.UDPPorts.Equal(o.UDPPorts) && p.ICMPEnabled.Equal(o.ICMPEnabled) &&
+		p.ServiceIds.Equal(o.ServiceIds) && p.Ports.Equal(o.Ports) &&
+		p.Name.Equal(o.Name) && p.Description.Equal(o.Description)
+}
+
+// FillUnknownPeerRule sets values of rule not known until applied, like ids of labels selected by key and value,
+// from the equivalent rule as read. Known values are kept as planned.
+func FillUnknownPeerRule(rule *PeerRuleModel, read *PeerRuleModel) {
+	for i := range rule.Selectors {
+		l := &rule.Selectors[i]
+		if !l.Id.IsUnknown() {
+			continue
+		}
+		for _, rl := range read.Selectors {
+			if rl.Key.Equal(l.Key) && rl.Value.Equal(l.Value) {
+				l.Id = rl.Id
+				break
+			}
+		}
+	}
+	strs := []struct{ target, value *types.String }{
+		{&rule.Type, &read.Type},
+		{&rule.SecurityGroupId, &read.SecurityGroupId},
+		{&rule.Vm, &read.Vm},
+	}
+	for _, v := range strs {
+		if v.target.IsUnknown() {
+			*v.target = *v.value
+		}
+	}
+	bools := []struct{ target, value *types.Bool }{
+		{&rule.TCPEnabled, &read.TCPEnabled},
+		{&rule.UDPEnabled, &read.UDPEnabled},
+		{&rule.ICMPEnabled, &read.ICMPEnabled},
+	}
+	for _, v := range bools {
+		if v.target.IsUnknown() {
+			*v.target = *v.value
+		}
+	}
+	lists := []struct{ target, value *types.List }{
+		{&rule.ExceptIPBlock, &read.ExceptIPBlock},
+		{&rule.ServiceIds, &read.ServiceIds},
+		{&rule.Ports, &read.Ports},
+	}
+	for _, v := range lists {
+		if v.target.IsUnknown() {
+			*v.target = *v.value
+		}
+	}
+	if rule.IPBlock.IsUnknown() {
+		rule.IPBlock = read.IPBlock
+	}
+	if rule.TCPPorts.IsUnknown() {
+		rule.TCPPorts = read.TCPPorts
+	}
+	if rule.UDPPorts.IsUnknown() {
+		rule.UDPPorts = read.UDPPorts
+	}
+}
+
+// BuildPeerRuleInput converts rule to everoute rule input of its peer type.
+func BuildPeerRuleInput(ctx context.Context, api *apiclient.Cloudtower, peer *PeerRuleModel) (map[string]interface{}, diag.Diagnostics) {
+	rule := peer.NetworkPolicyRule()
+	switch peer.Type.ValueString() {
+	case PeerTypeSelector:
+		services, diags := BuildNetworkPolicyRuleServices(ctx, &rule)
+		ports, d := BuildNetworkPolicyRulePorts(ctx, &rule)
+		diags.Append(d...)
+		ids, d := label_helper.ResolveLabelIds(api, peer.Selectors)
+		diags.Append(d...)
+		return map[string]interface{}{
+			"type":         PeerTypeSelector,
+			"ports":        ports,
+			"services":     services,
+			"selector_ids": ids,
+		}, diags
+	case PeerTypeSecurityGroup:
+		services, diags := BuildNetworkPolicyRuleServices(ctx, &rule)
+		ports, d := BuildNetworkPolicyRulePorts(ctx, &rule)
+		diags.Append(d...)
+		return map[string]interface{}{
+			"type":              PeerTypeSecurityGroup,
+			"ports":             ports,
+			"services":          services,
+			"security_group_id": peer.SecurityGroupId.ValueString(),
+		}, diags
+	case PeerTypeVM:
+		var diags diag.Diagnostics
+		diags.AddError(
+			"Unsupported network policy peer",
+			"vm peer is resolved to a rule for each of the vm's ips, configure it in ingress or egress of a policy",
+		)
+		return nil, diags
+	default:
+		return BuildNetworkPolicyRuleInput(ctx, &rule)
+	}
+}
+
+func BuildPeerRulesInput(ctx context.Context, api *apiclient.Cloudtower, peers []PeerRuleModel) ([]map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	inputs := make([]map[string]interface{}, 0, len(peers))
+	for _, peer := range peers {
+		peer := peer
+		if peer.Type.ValueString() == PeerTypeVM {
+			blocks, d := vmPeerIPBlocks(api, peer.Vm.ValueString())
+			diags.Append(d...)
+			for _, rule := range expandVmPeerRule(&peer, blocks) {
+				rule := rule
+				input, d := BuildPeerRuleInput(ctx, api, &rule)
+				diags.Append(d...)
+				inputs = append(inputs, input)
+			}
+			continue
+		}
+		input, d := BuildPeerRuleInput(ctx, api, &peer)
+		diags.Append(d...)
+		inputs = append(inputs, input)
+	}
+	return inputs, diags
+}
+
+func ReadGqlResultToPeerRules(input *gjson.Result) []PeerRuleModel {
+	rules := input.Array()
+	result := make([]PeerRuleModel, len(rules))
+	for idx, jrule := range rules {
+		jrule := jrule
+		rule := ReadGqlResultToNetworkPolicyRuleModel(&jrule)
+		peer := PeerRuleModel{
+			Type:            types.StringValue(jrule.Get("type").String()),
+			IPBlock:         ip_helper.NewIPBlockNull(),
+			ExceptIPBlock:   rule.ExceptIPBlock,
+			SecurityGroupId: types.StringNull(),
+			Vm:              types.StringNull(),
+			TCPEnabled:      rule.TCPEnabled,
+			TCPPorts:        rule.TCPPorts,
+			UDPEnabled:      rule.UDPEnabled,
+			UDPPorts:        rule.UDPPorts,
+			ICMPEnabled:     rule.ICMPEnabled,
+			ServiceIds:      rule.ServiceIds,
+			Ports:           rule.Ports,
+			Name:            types.StringNull(),
+			Description:     types.StringNull(),
+		}
+		// rules created by old version cloudtower have no type
+		if peer.Type.ValueString() == "" {
+			peer.Type = types.StringValue(PeerTypeIPBlock)
+		}
+		switch peer.Type.ValueString() {
+		case PeerTypeSelector:
+			for _, jl := range jrule.Get("selector").Array() {
+				jl := jl
+				var l label_helper.LabelModel
+				label_helper.ReadGJsonLabelToModel(&jl, &l)
+				peer.Selectors = append(peer.Selectors, l)
+			}
+		case PeerTypeSecurityGroup:
+			peer.SecurityGroupId = types.StringValue(jrule.Get("security_group_id").String())
+		default:
+			peer.IPBlock = rule.IPBlock
+		}
+		result[idx] = peer
+	}
+	return result
+}
+
+// ValidatePeerRuleNames reports names used by more than one rule of a direction.
+func ValidatePeerRuleNames(rules []PeerRuleModel, direction string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if rule.Name.IsNull() || rule.Name.IsUnknown() {
+			continue
+		}
+		name := rule.Name.ValueString()
+		if seen[name] {
+			diags.AddAttributeError(path.Root(direction), "Invalid "+direction,
+				fmt.Sprintf("rule name %q is used by more than one %s rule", name, direction))
+		}
+		seen[name] = true
+	}
+	return diags
+}
@@ -0,0 +1,105 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var _ validator.Object = PeerRuleValidator{}
+
+func GetPeerRuleValidator() validator.Object {
+	return PeerRuleValidator{}
+}
+
+type PeerRuleValidator struct{}
+
+func (v PeerRuleValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v PeerRuleValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate network policy rule peer, make sure only fields of the peer type are configured"
+}
+
+func (v PeerRuleValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	attrs := req.ConfigValue.Attributes()
+	peerType, _ := attrs["type"].(types.String)
+	if peerType.IsUnknown() {
+		// temporary ignore unknown value
+		return
+	}
+	t := PeerTypeIPBlock
+	if !peerType.IsNull() {
+		t = peerType.ValueString()
+	}
+	CheckPeerFields(req, resp, t, map[string]bool{
+		"ip_block":          IsSet(attrs["ip_block"]),
+		"except_ip_block":   IsSet(attrs["except_ip_block"]),
+		"selectors":         IsSet(attrs["selectors"]),
+		"security_group_id": IsSet(attrs["security_group_id"]),
+		"vm":                IsSet(attrs["vm"]),
+	}, map[string][]string{
+		PeerTypeIPBlock:       {"ip_block"},
+		PeerTypeSelector:      {"selectors"},
+		PeerTypeSecurityGroup: {"security_group_id"},
+		PeerTypeVM:            {"vm"},
+	}, map[string][]string{
+		PeerTypeIPBlock: {"except_ip_block"},
+	})
+}
+
+// CheckPeerFields makes sure fields required by peer type are set, and fields of other types are not set.
+func CheckPeerFields(req validator.ObjectRequest, resp *validator.ObjectResponse, t string, set map[string]bool, required map[string][]string, optional map[string][]string) {
+	allowed := make(map[string]bool)
+	for _, f := range required[t] {
+		allowed[f] = true
+		if !set[f] {
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"invalid network policy peer",
+				fmt.Sprintf("%s is required when type is %s", f, t),
+			)
+		}
+	}
+	for _, f := range optional[t] {
+		allowed[f] = true
+	}
+	fields := make([]string, 0, len(set))
+	for f := range set {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		if set[f] && !allowed[f] {
+			resp.Diagnostics.AddAttributeError(
+				req.Path,
+				"invalid network policy peer",
+				fmt.Sprintf("%s is not allowed when type is %s", f, t),
+			)
+		}
+	}
+}
+
+func IsSet(v attr.Value) bool {
+	if v == nil {
+		return false
+	}
+	if v.IsUnknown() {
+		return true
+	}
+	if v.IsNull() {
+		return false
+	}
+	if l, ok := v.(types.List); ok {
+		return len(l.Elements()) > 0
+	}
+	return true
+}
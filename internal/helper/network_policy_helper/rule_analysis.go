@@ -0,0 +1,456 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+)
+
+// RuleShadow describes a rule whose traffic is fully allowed by another rule of the same direction.
+// Rules are described by content as well as index, since rules of a set have no stable position.
+type RuleShadow struct {
+	Index        int
+	CoveredBy    int
+	Duplicate    bool
+	Rule         string
+	CoveringRule string
+}
+
+func (s RuleShadow) Message(direction string) string {
+	if s.Duplicate {
+		return fmt.Sprintf("%s rule (%s) duplicates %s rule (%s)", direction, s.Rule, direction, s.CoveringRule)
+	}
+	return fmt.Sprintf("%s rule (%s) is fully covered by %s rule (%s)", direction, s.Rule, direction, s.CoveringRule)
+}
+
+// analyzedRule is the comparable form of a rule.
+type analyzedRule struct {
+	name      string
+	peerType  string
+	ipBlock   netip.Prefix
+	excepts   []netip.Prefix
+	peerKey   string
+	protocols *ruleProtocols
+	services  map[string]bool
+	// a rule without ports entries and network services allows all protocols, IPIP and ALG included
+	allProtocols bool
+}
+
+// AnalyzePeerRules finds rules of one direction covered by or duplicating an earlier or broader rule,
+// rules with unknown or invalid values are skipped.
+func AnalyzePeerRules(ctx context.Context, rules []PeerRuleModel) []RuleShadow {
+	analyzed := make([]*analyzedRule, len(rules))
+	for i := range rules {
+		analyzed[i] = analyzePeerRule(ctx, &rules[i])
+	}
+	shadows := make([]RuleShadow, 0)
+	for i, b := range analyzed {
+		if b == nil {
+			continue
+		}
+		var shadow *RuleShadow
+		for j, a := range analyzed {
+			if i == j || a == nil || !a.covers(b) {
+				continue
+			}
+			duplicate := b.covers(a)
+			// report duplicates once, on the later rule
+			if duplicate && j > i {
+				continue
+			}
+			// prefer reporting a duplicate to a broader rule
+			if shadow == nil || (duplicate && !shadow.Duplicate) {
+				shadow = &RuleShadow{Index: i, CoveredBy: j, Duplicate: duplicate, Rule: b.String(), CoveringRule: a.String()}
+			}
+		}
+		if shadow != nil {
+			shadows = append(shadows, *shadow)
+		}
+	}
+	return shadows
+}
+
+// AlignPeerRules orders rules like the prior rules they duplicate, rules without a duplicate follow.
+// Semantic equality of set elements compares them by position, aligned rules keep prior values like
+// an ip written without prefix length. Rules as read have no name and description and are read in one form
+// of protocols, the prior ones are kept.
+func AlignPeerRules(ctx context.Context, rules []PeerRuleModel, prior []PeerRuleModel) []PeerRuleModel {
+	analyzed := make([]*analyzedRule, len(rules))
+	for i := range rules {
+		analyzed[i] = analyzePeerRule(ctx, &rules[i])
+	}
+	used := make([]bool, len(rules))
+	aligned := make([]PeerRuleModel, 0, len(rules))
+	for i := range prior {
+		p := analyzePeerRule(ctx, &prior[i])
+		if p == nil {
+			continue
+		}
+		for j, r := range analyzed {
+			if !used[j] && r != nil && r.covers(p) && p.covers(r) {
+				used[j] = true
+				rule := rules[j]
+				keepPriorRule(&rule, &prior[i])
+				aligned = append(aligned, rule)
+				break
+			}
+		}
+	}
+	for j := range rules {
+		if !used[j] {
+			aligned = append(aligned, rules[j])
+		}
+	}
+	return aligned
+}
+
+// KeepPeerRuleNames sets name, description and form of protocols of rules as read from the prior rules
+// they duplicate, preferring the prior rule of the same position, rules are kept in order.
+func KeepPeerRuleNames(ctx context.Context, rules []PeerRuleModel, prior []PeerRuleModel) []PeerRuleModel {
+	analyzed := make([]*analyzedRule, len(prior))
+	for i := range prior {
+		analyzed[i] = analyzePeerRule(ctx, &prior[i])
+	}
+	used := make([]bool, len(prior))
+	duplicates := func(r *analyzedRule, i int) bool {
+		return !used[i] && analyzed[i] != nil && r.covers(analyzed[i]) && analyzed[i].covers(r)
+	}
+	for j := range rules {
+		r := analyzePeerRule(ctx, &rules[j])
+		if r == nil {
+			continue
+		}
+		match := -1
+		if j < len(prior) && duplicates(r, j) {
+			match = j
+		}
+		for i := 0; match < 0 && i < len(prior); i++ {
+			if duplicates(r, i) {
+				match = i
+			}
+		}
+		if match >= 0 {
+			used[match] = true
+			keepPriorRule(&rules[j], &prior[match])
+		}
+	}
+	return rules
+}
+
+// keepPriorRule sets values of a rule as read from the duplicated prior rule, name and description which are
+// not read, and protocols in the form they were configured, values not known yet are left as read.
+func keepPriorRule(rule *PeerRuleModel, prior *PeerRuleModel) {
+	rule.Name = prior.Name
+	rule.Description = prior.Description
+	if !prior.Ports.IsUnknown() {
+		rule.Ports = prior.Ports
+	}
+	for _, v := range []struct{ target, value *types.Bool }{
+		{&rule.TCPEnabled, &prior.TCPEnabled},
+		{&rule.UDPEnabled, &prior.UDPEnabled},
+		{&rule.ICMPEnabled, &prior.ICMPEnabled},
+	} {
+		if !v.value.IsUnknown() {
+			*v.target = *v.value
+		}
+	}
+	if !prior.TCPPorts.IsUnknown() {
+		rule.TCPPorts = prior.TCPPorts
+	}
+	if !prior.UDPPorts.IsUnknown() {
+		rule.UDPPorts = prior.UDPPorts
+	}
+}
+
+// AllowsIPBlock reports if an ip block rule allows tcp traffic of the whole block on ports, empty ports mean all ports,
+// known is false if the block may be allowed by a rule not known yet.
+func AllowsIPBlock(ctx context.Context, rules []PeerRuleModel, block netip.Prefix, ports string) (allowed bool, known bool) {
+	known = true
+	target := &analyzedRule{peerType: PeerTypeIPBlock, ipBlock: block}
+	for i := range rules {
+		r := analyzePeerRule(ctx, &rules[i])
+		if r == nil {
+			known = false
+			continue
+		}
+		if r.peerType == PeerTypeIPBlock && r.coversIPBlock(target) && r.protocols.TCPEnabled && coversPorts(r.protocols.TCPPorts, ports) {
+			return true, true
+		}
+	}
+	return false, known
+}
+
+// analyzePeerRule returns nil if the rule is not known yet or invalid.
+func analyzePeerRule(ctx context.Context, peer *PeerRuleModel) *analyzedRule {
+	if peer.Type.IsUnknown() || peer.Ports.IsUnknown() || peer.ServiceIds.IsUnknown() {
+		return nil
+	}
+	rule := &analyzedRule{name: peer.Name.ValueString(), peerType: peer.Type.ValueString()}
+	switch rule.peerType {
+	case PeerTypeSelector:
+		keys := make([]string, 0, len(peer.Selectors))
+		for _, l := range peer.Selectors {
+			// labels are compared by key and value, id is unknown in plan if label is selected by them
+			switch {
+			case isKnown(l.Key) && isKnown(l.Value):
+				keys = append(keys, l.Key.ValueString()+"="+l.Value.ValueString())
+			case isKnown(l.Id):
+				keys = append(keys, "id:"+l.Id.ValueString())
+			default:
+				return nil
+			}
+		}
+		sort.Strings(keys)
+		rule.peerKey = strings.Join(keys, ",")
+	case PeerTypeSecurityGroup:
+		if peer.SecurityGroupId.IsUnknown() {
+			return nil
+		}
+		rule.peerKey = peer.SecurityGroupId.ValueString()
+	case PeerTypeVM:
+		// ips of the vm are not known until applied, vm peers are compared by reference
+		if peer.Vm.IsUnknown() {
+			return nil
+		}
+		rule.peerKey = peer.Vm.ValueString()
+	default:
+		if peer.IPBlock.IsUnknown() || peer.ExceptIPBlock.IsUnknown() {
+			return nil
+		}
+		prefix, err := ip_helper.ParseIPBlock(peer.IPBlock.ValueString())
+		if err != nil {
+			return nil
+		}
+		rule.ipBlock = prefix
+		for _, e := range peer.ExceptIPBlock.Elements() {
+			ep, ok := ipBlockPrefix(e)
+			if !ok {
+				return nil
+			}
+			rule.excepts = append(rule.excepts, ep)
+		}
+	}
+	r := peer.NetworkPolicyRule()
+	entries, diags := rulePortEntries(ctx, &r)
+	if diags.HasError() {
+		return nil
+	}
+	rule.services = make(map[string]bool)
+	for _, s := range peer.ServiceIds.Elements() {
+		id, ok := s.(types.String)
+		if !ok || id.IsUnknown() {
+			return nil
+		}
+		rule.services[id.ValueString()] = true
+	}
+	p := normalizePortEntries(entries, len(rule.services) > 0)
+	rule.protocols = &p
+	rule.allProtocols = len(entries) == 0 && len(rule.services) == 0
+	return rule
+}
+
+func ipBlockPrefix(v attr.Value) (netip.Prefix, bool) {
+	block, ok := v.(ip_helper.IPBlock)
+	if !ok || block.IsUnknown() || block.IsNull() {
+		return netip.Prefix{}, false
+	}
+	prefix, err := ip_helper.ParseIPBlock(block.ValueString())
+	return prefix, err == nil
+}
+
+// String describes the peer and protocols of the rule.
+func (r *analyzedRule) String() string {
+	parts := make([]string, 0)
+	if r.name != "" {
+		parts = append(parts, fmt.Sprintf("name %q", r.name))
+	}
+	switch r.peerType {
+	case PeerTypeSelector:
+		parts = append(parts, "selectors "+r.peerKey)
+	case PeerTypeSecurityGroup:
+		parts = append(parts, "security_group_id "+r.peerKey)
+	case PeerTypeVM:
+		parts = append(parts, "vm "+r.peerKey)
+	default:
+		parts = append(parts, "ip_block "+r.ipBlock.String())
+		for _, e := range r.excepts {
+			parts = append(parts, "except "+e.String())
+		}
+	}
+	if r.allProtocols {
+		parts = append(parts, "all protocols")
+		return strings.Join(parts, ", ")
+	}
+	protocols := []struct {
+		name    string
+		enabled bool
+		ports   string
+	}{
+		{"tcp", r.protocols.TCPEnabled, r.protocols.TCPPorts},
+		{"udp", r.protocols.UDPEnabled, r.protocols.UDPPorts},
+		{"icmp", r.protocols.ICMPEnabled, ""},
+	}
+	for _, p := range protocols {
+		if !p.enabled {
+			continue
+		}
+		if p.ports == "" {
+			parts = append(parts, p.name)
+		} else {
+			parts = append(parts, p.name+" "+p.ports)
+		}
+	}
+	if r.protocols.Others != "" {
+		parts = append(parts, strings.ToLower(strings.ReplaceAll(r.protocols.Others, ";", ", ")))
+	}
+	if len(r.services) > 0 {
+		ids := make([]string, 0, len(r.services))
+		for id := range r.services {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		parts = append(parts, "network services "+strings.Join(ids, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// covers reports if all traffic allowed by other is allowed by r.
+func (r *analyzedRule) covers(other *analyzedRule) bool {
+	if r.peerType != other.peerType {
+		return false
+	}
+	if r.peerType == PeerTypeSelector || r.peerType == PeerTypeSecurityGroup || r.peerType == PeerTypeVM {
+		if r.peerKey != other.peerKey {
+			return false
+		}
+	} else if !r.coversIPBlock(other) {
+		return false
+	}
+	if r.allProtocols {
+		return true
+	}
+	if other.allProtocols {
+		return false
+	}
+	for id := range other.services {
+		if !r.services[id] {
+			return false
+		}
+	}
+	return coversProtocols(r.protocols, other.protocols)
+}
+
+// coversIPBlock reports if ip block minus excepts of r contains the one of other.
+func (r *analyzedRule) coversIPBlock(other *analyzedRule) bool {
+	if !ip_helper.ContainsIPBlock(r.ipBlock, other.ipBlock) {
+		return false
+	}
+	for _, e := range r.excepts {
+		if !e.Overlaps(other.ipBlock) {
+			continue
+		}
+		// cidrs either nest or are disjoint, an except overlapping other must be excepted by other too
+		excepted := false
+		for _, oe := range other.excepts {
+			if ip_helper.ContainsIPBlock(oe, e) {
+				excepted = true
+				break
+			}
+		}
+		if !excepted {
+			return false
+		}
+	}
+	return true
+}
+
+func coversProtocols(r *ruleProtocols, other *ruleProtocols) bool {
+	if other.TCPEnabled && (!r.TCPEnabled || !coversPorts(r.TCPPorts, other.TCPPorts)) {
+		return false
+	}
+	if other.UDPEnabled && (!r.UDPEnabled || !coversPorts(r.UDPPorts, other.UDPPorts)) {
+		return false
+	}
+	if other.ICMPEnabled && !r.ICMPEnabled {
+		return false
+	}
+	if other.Others == "" {
+		return true
+	}
+	others := make(map[string]bool)
+	for _, o := range strings.Split(r.Others, ";") {
+		others[o] = true
+	}
+	for _, o := range strings.Split(other.Others, ";") {
+		if !others[o] && !coversICMPType(r, others, o) {
+			return false
+		}
+	}
+	return true
+}
+
+// coversICMPType reports if an ICMP entry of an icmp type like ICMP/3/4 is covered by
+// an entry of all icmp types or of all codes of the type.
+func coversICMPType(r *ruleProtocols, others map[string]bool, key string) bool {
+	parts := strings.Split(key, "/")
+	if parts[0] != ProtocolICMP || len(parts) < 2 {
+		return false
+	}
+	return r.ICMPEnabled || (len(parts) == 3 && others[parts[0]+"/"+parts[1]])
+}
+
+// coversPorts reports if port spec covers other, empty spec means all ports.
+// Ranges of several entries may overlap or be adjacent, they are merged before compared.
+func coversPorts(spec string, other string) bool {
+	if spec == "" {
+		return true
+	}
+	if other == "" {
+		return false
+	}
+	ranges, ok := mergedPortRanges(spec)
+	if !ok {
+		return false
+	}
+	otherRanges, ok := mergedPortRanges(other)
+	if !ok {
+		return false
+	}
+	for _, o := range otherRanges {
+		covered := false
+		for _, r := range ranges {
+			if r.From <= o.From && o.To <= r.To {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// mergedPortRanges parses spec item by item, as merged specs of several entries may overlap.
+func mergedPortRanges(spec string) ([]port_helper.PortRange, bool) {
+	ranges := make([]port_helper.PortRange, 0)
+	for _, item := range strings.Split(spec, ",") {
+		parsed, err := port_helper.ParsePortSpec(item)
+		if err != nil || len(parsed) == 0 {
+			return nil, false
+		}
+		ranges = append(ranges, parsed...)
+	}
+	return port_helper.MergePortRanges(ranges), true
+}
+
+func isKnown(v types.String) bool {
+	return !v.IsNull() && !v.IsUnknown()
+}
@@ -0,0 +1,186 @@
+package network_policy_helper
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+)
+
+func TestCoversPorts(t *testing.T) {
+	cases := []struct {
+		name  string
+		spec  string
+		other string
+		want  bool
+	}{
+		{"all ports cover a port", "", "22", true},
+		{"a port does not cover all ports", "22", "", false},
+		{"same port", "22", "22", true},
+		{"port in a list", "80,443", "443", true},
+		{"other port", "80", "81", false},
+		{"range in a wider range", "1-1024", "80-90", true},
+		{"range across adjacent ranges", "1-100,101-200", "50-150", true},
+		{"range across a gap", "1-100,102-200", "50-150", false},
+		{"overlapping ranges of several entries", "1-100,50-150", "120", true},
+		{"ports across adjacent single ports", "80,81,82", "80-82", true},
+		{"invalid spec", "80-", "80", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := coversPorts(c.spec, c.other); got != c.want {
+				t.Errorf("coversPorts(%q, %q) = %v, want %v", c.spec, c.other, got, c.want)
+			}
+		})
+	}
+}
+
+func TestRuleCoversProtocols(t *testing.T) {
+	entries := func(protocols ...string) []PortEntryModel {
+		result := make([]PortEntryModel, 0, len(protocols))
+		for _, p := range protocols {
+			e := newPortEntry(p, "")
+			if p == ProtocolALG {
+				e.AlgProtocol = types.StringValue("FTP")
+			}
+			result = append(result, e)
+		}
+		return result
+	}
+	cases := []struct {
+		name  string
+		rule  []PortEntryModel
+		other []PortEntryModel
+		want  bool
+	}{
+		{"no entries cover IPIP", entries(), entries(ProtocolIPIP), true},
+		{"no entries cover ALG", entries(), entries(ProtocolALG), true},
+		{"tcp, udp and icmp do not cover IPIP", entries(ProtocolTCP, ProtocolUDP, ProtocolICMP), entries(ProtocolIPIP), false},
+		{"tcp, udp and icmp do not cover no entries", entries(ProtocolTCP, ProtocolUDP, ProtocolICMP), entries(), false},
+		{"IPIP covers IPIP", entries(ProtocolIPIP), entries(ProtocolIPIP), true},
+		{"IPIP does not cover ALG", entries(ProtocolIPIP), entries(ProtocolALG), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule := analyzePeerRule(context.Background(), testIPBlockRule("10.0.0.0/24", c.rule))
+			other := analyzePeerRule(context.Background(), testIPBlockRule("10.0.0.1", c.other))
+			if rule == nil || other == nil {
+				t.Fatal("rules are not analyzed")
+			}
+			if got := rule.covers(other); got != c.want {
+				t.Errorf("(%s) covers (%s) = %v, want %v", rule, other, got, c.want)
+			}
+		})
+	}
+}
+
+func testIPBlockRule(block string, entries []PortEntryModel) *PeerRuleModel {
+	return &PeerRuleModel{
+		Type:            types.StringValue(PeerTypeIPBlock),
+		IPBlock:         ip_helper.NewIPBlockValue(block),
+		ExceptIPBlock:   ip_helper.IPBlockListValue(nil),
+		SecurityGroupId: types.StringNull(),
+		Vm:              types.StringNull(),
+		TCPEnabled:      types.BoolNull(),
+		TCPPorts:        port_helper.NewPortSpecNull(),
+		UDPEnabled:      types.BoolNull(),
+		UDPPorts:        port_helper.NewPortSpecNull(),
+		ICMPEnabled:     types.BoolNull(),
+		ServiceIds:      types.ListValueMust(types.StringType, nil),
+		Ports:           portEntriesValue(entries),
+		Name:            types.StringNull(),
+		Description:     types.StringNull(),
+	}
+}
+
+func TestAllowsIPBlock(t *testing.T) {
+	tcp := func(port string) []PortEntryModel {
+		return []PortEntryModel{newPortEntry(ProtocolTCP, port)}
+	}
+	target := netip.MustParsePrefix("10.0.0.1/32")
+	cases := []struct {
+		name  string
+		rules []*PeerRuleModel
+		ports string
+		want  bool
+	}{
+		{"all protocols", []*PeerRuleModel{testIPBlockRule("10.0.0.0/24", nil)}, "80", true},
+		{"management port", []*PeerRuleModel{testIPBlockRule("10.0.0.0/24", tcp("80,443"))}, "443", true},
+		{"other port", []*PeerRuleModel{testIPBlockRule("10.0.0.0/24", tcp("22"))}, "443", false},
+		{"some ports of all", []*PeerRuleModel{testIPBlockRule("10.0.0.0/24", tcp("1-1024"))}, "", false},
+		{"other block", []*PeerRuleModel{testIPBlockRule("10.0.1.0/24", tcp(""))}, "443", false},
+		{"udp only", []*PeerRuleModel{testIPBlockRule("10.0.0.0/24", []PortEntryModel{newPortEntry(ProtocolUDP, "")})}, "443", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rules := make([]PeerRuleModel, 0, len(c.rules))
+			for _, r := range c.rules {
+				rules = append(rules, *r)
+			}
+			allowed, known := AllowsIPBlock(context.Background(), rules, target, c.ports)
+			if allowed != c.want || !known {
+				t.Errorf("AllowsIPBlock(%q) = %v, %v, want %v, true", c.ports, allowed, known, c.want)
+			}
+		})
+	}
+}
+
+func TestRuleCoversICMPTypes(t *testing.T) {
+	icmp := func(icmpType int64, icmpCode int64) PortEntryModel {
+		e := newPortEntry(ProtocolICMP, "")
+		if icmpType >= 0 {
+			e.ICMPType = types.Int64Value(icmpType)
+		}
+		if icmpCode >= 0 {
+			e.ICMPCode = types.Int64Value(icmpCode)
+		}
+		return e
+	}
+	cases := []struct {
+		name  string
+		rule  []PortEntryModel
+		other []PortEntryModel
+		want  bool
+	}{
+		{"all types cover a type", []PortEntryModel{icmp(-1, -1)}, []PortEntryModel{icmp(8, -1)}, true},
+		{"a type does not cover all types", []PortEntryModel{icmp(8, -1)}, []PortEntryModel{icmp(-1, -1)}, false},
+		{"a type covers its codes", []PortEntryModel{icmp(3, -1)}, []PortEntryModel{icmp(3, 4)}, true},
+		{"a code does not cover its type", []PortEntryModel{icmp(3, 4)}, []PortEntryModel{icmp(3, -1)}, false},
+		{"other type", []PortEntryModel{icmp(8, -1)}, []PortEntryModel{icmp(0, -1)}, false},
+		{"same type and code", []PortEntryModel{icmp(3, 4)}, []PortEntryModel{icmp(3, 4)}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rule := analyzePeerRule(context.Background(), testIPBlockRule("10.0.0.0/24", c.rule))
+			other := analyzePeerRule(context.Background(), testIPBlockRule("10.0.0.1", c.other))
+			if rule == nil || other == nil {
+				t.Fatal("rules are not analyzed")
+			}
+			if got := rule.covers(other); got != c.want {
+				t.Errorf("(%s) covers (%s) = %v, want %v", rule, other, got, c.want)
+			}
+		})
+	}
+}
+
+func TestPeerRuleInputKeyOfICMPTypes(t *testing.T) {
+	key := func(ports ...map[string]interface{}) string {
+		return PeerRuleInputKey(map[string]interface{}{"type": PeerTypeIPBlock, "ip_block": "10.0.0.1", "ports": ports})
+	}
+	all := map[string]interface{}{"protocol": "ICMP"}
+	echo := map[string]interface{}{"protocol": "ICMP", "icmp_type": int64(8)}
+	echoCode := map[string]interface{}{"protocol": "ICMP", "icmp_type": int64(8), "icmp_code": int64(0)}
+	if key(echo) == key(all) || key(echo) == key(echoCode) {
+		t.Error("rules of different icmp types or codes share a key")
+	}
+	if key(all, echo) != key(all) {
+		t.Error("an icmp type entry covered by all icmp types changes the key")
+	}
+	// inputs decoded from json hold numbers as float64
+	if key(map[string]interface{}{"protocol": "ICMP", "icmp_type": float64(8)}) != key(echo) {
+		t.Error("key of a decoded input differs from the built one")
+	}
+}
@@ -0,0 +1,205 @@
+package network_policy_helper
+
+import (
+	"context"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+// algPorts are the control ports whose connections alg protocols track.
+var algPorts = map[string]struct {
+	protocol string
+	port     int64
+}{
+	"FTP":  {ProtocolTCP, 21},
+	"TFTP": {ProtocolUDP, 69},
+}
+
+// Traffic is a connection evaluated against rules, Port is the destination port of tcp and udp,
+// ICMPType and ICMPCode are -1 for any icmp type and code.
+type Traffic struct {
+	Protocol string
+	Port     int64
+	ICMPType int64
+	ICMPCode int64
+}
+
+// MatchGqlResultRule reports if a rule as read allows traffic from or to peer,
+// evaluable is false for selector and security group rules whose peers are not known by ip.
+// services holds members of network services referenced by the rule, by service id.
+func MatchGqlResultRule(rule *gjson.Result, services map[string][]gjson.Result, peer netip.Addr, traffic Traffic) (matched bool, evaluable bool) {
+	switch rule.Get("type").String() {
+	case PeerTypeSelector, PeerTypeSecurityGroup:
+		return false, false
+	}
+	block, err := ip_helper.ParseIPBlock(rule.Get("ip_block").String())
+	if err != nil || !block.Contains(peer) {
+		return false, true
+	}
+	for _, je := range rule.Get("except_ip_block").Array() {
+		if except, err := ip_helper.ParseIPBlock(je.String()); err == nil && except.Contains(peer) {
+			return false, true
+		}
+	}
+
+	jports := rule.Get("ports").Array()
+	jservices := rule.Get("services").Array()
+	// no entries without network services mean all protocols are allowed
+	if len(jports) == 0 && len(jservices) == 0 {
+		return true, true
+	}
+	for _, jp := range jports {
+		if matchPortEntry(&jp, traffic) {
+			return true, true
+		}
+	}
+	for _, js := range jservices {
+		for _, jm := range services[js.String()] {
+			jm := jm
+			if matchPortEntry(&jm, traffic) {
+				return true, true
+			}
+		}
+	}
+	return false, true
+}
+
+// matchPortEntry matches a rule port entry or a network service member.
+func matchPortEntry(entry *gjson.Result, traffic Traffic) bool {
+	protocol := strings.ToUpper(entry.Get("protocol").String())
+	switch protocol {
+	case ProtocolTCP, ProtocolUDP:
+		return protocol == traffic.Protocol && matchPort(entry.Get("port").String(), traffic.Port)
+	case ProtocolICMP:
+		if protocol != traffic.Protocol {
+			return false
+		}
+		jt := entry.Get("icmp_type")
+		jc := entry.Get("icmp_code")
+		return (jt.Type == gjson.Null || jt.Int() == traffic.ICMPType) && (jc.Type == gjson.Null || jc.Int() == traffic.ICMPCode)
+	case ProtocolALG:
+		alg, ok := algPorts[strings.ToUpper(entry.Get("alg_protocol").String())]
+		return ok && alg.protocol == traffic.Protocol && alg.port == traffic.Port
+	default:
+		return protocol == traffic.Protocol
+	}
+}
+
+// matchPort reports if port spec contains port, empty spec means all ports.
+func matchPort(spec string, port int64) bool {
+	if spec == "" {
+		return true
+	}
+	ranges, err := port_helper.ParsePortSpec(spec)
+	if err != nil {
+		return false
+	}
+	for _, r := range ranges {
+		if r.From <= port && port <= r.To {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribePeerRule describes a rule by its name and content, like in rule analysis warnings.
+func DescribePeerRule(ctx context.Context, rule *PeerRuleModel) string {
+	if r := analyzePeerRule(ctx, rule); r != nil {
+		return r.String()
+	}
+	return ""
+}
+
+// GqlResultRulePeerCovers reports if the peer of a rule as read contains the whole block,
+// or any part of it if overlapping is true. evaluable is false for selector and security group rules.
+func GqlResultRulePeerCovers(rule *gjson.Result, block netip.Prefix, overlapping bool) (matched bool, evaluable bool) {
+	switch rule.Get("type").String() {
+	case PeerTypeSelector, PeerTypeSecurityGroup:
+		return false, false
+	}
+	ipBlock, err := ip_helper.ParseIPBlock(rule.Get("ip_block").String())
+	if err != nil {
+		return false, true
+	}
+	if overlapping {
+		if !ipBlock.Overlaps(block) {
+			return false, true
+		}
+		// the block overlaps the rule unless excepts remove all of it
+		for _, je := range rule.Get("except_ip_block").Array() {
+			if except, err := ip_helper.ParseIPBlock(je.String()); err == nil && ip_helper.ContainsIPBlock(except, block) {
+				return false, true
+			}
+		}
+		return true, true
+	}
+	if !ip_helper.ContainsIPBlock(ipBlock, block) {
+		return false, true
+	}
+	for _, je := range rule.Get("except_ip_block").Array() {
+		if except, err := ip_helper.ParseIPBlock(je.String()); err == nil && except.Overlaps(block) {
+			return false, true
+		}
+	}
+	return true, true
+}
+
+// GqlResultRuleAllowsPorts reports if a rule as read allows any port in ranges of protocol,
+// no ranges mean any port. services holds members of network services referenced by the rule, by service id.
+func GqlResultRuleAllowsPorts(rule *gjson.Result, services map[string][]gjson.Result, protocol string, ranges []port_helper.PortRange) bool {
+	jports := rule.Get("ports").Array()
+	jservices := rule.Get("services").Array()
+	// no entries without network services mean all protocols are allowed
+	if len(jports) == 0 && len(jservices) == 0 {
+		return true
+	}
+	entries := jports
+	for _, js := range jservices {
+		entries = append(entries, services[js.String()]...)
+	}
+	for _, je := range entries {
+		entryProtocol := strings.ToUpper(je.Get("protocol").String())
+		spec := je.Get("port").String()
+		if entryProtocol == ProtocolALG {
+			alg, ok := algPorts[strings.ToUpper(je.Get("alg_protocol").String())]
+			if !ok {
+				continue
+			}
+			entryProtocol, spec = alg.protocol, strconv.FormatInt(alg.port, 10)
+		}
+		if entryProtocol != protocol {
+			continue
+		}
+		if protocol != ProtocolTCP && protocol != ProtocolUDP {
+			return true
+		}
+		if overlapsPorts(spec, ranges) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsPorts reports if port spec shares a port with ranges, empty spec or ranges mean all ports.
+func overlapsPorts(spec string, ranges []port_helper.PortRange) bool {
+	if spec == "" || len(ranges) == 0 {
+		return true
+	}
+	specRanges, err := port_helper.ParsePortSpec(spec)
+	if err != nil {
+		return false
+	}
+	for _, s := range specRanges {
+		for _, r := range ranges {
+			if s.From <= r.To && r.From <= s.To {
+				return true
+			}
+		}
+	}
+	return false
+}
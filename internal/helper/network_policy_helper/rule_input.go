@@ -0,0 +1,186 @@
+package network_policy_helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/tidwall/gjson"
+)
+
+// ReadGqlResultToPeerRuleInputs converts rules as read to rule inputs,
+// so that rules not managed by terraform are written back unchanged.
+func ReadGqlResultToPeerRuleInputs(input *gjson.Result) []map[string]interface{} {
+	rules := make([]map[string]interface{}, 0)
+	for _, jrule := range input.Array() {
+		ports := make([]map[string]interface{}, 0)
+		for _, jport := range jrule.Get("ports").Array() {
+			port := map[string]interface{}{
+				"protocol": jport.Get("protocol").String(),
+			}
+			if jp := jport.Get("port"); jp.Exists() && jp.Type != gjson.Null {
+				port["port"] = jp.String()
+			}
+			if ja := jport.Get("alg_protocol"); ja.Exists() && ja.Type != gjson.Null {
+				port["alg_protocol"] = ja.String()
+			}
+			if jt := jport.Get("icmp_type"); jt.Exists() && jt.Type != gjson.Null {
+				port["icmp_type"] = jt.Int()
+			}
+			if jc := jport.Get("icmp_code"); jc.Exists() && jc.Type != gjson.Null {
+				port["icmp_code"] = jc.Int()
+			}
+			ports = append(ports, port)
+		}
+		services := make([]string, 0)
+		for _, js := range jrule.Get("services").Array() {
+			services = append(services, js.String())
+		}
+		rule := map[string]interface{}{
+			"ports":    ports,
+			"services": services,
+		}
+		switch t := jrule.Get("type").String(); t {
+		case PeerTypeSelector:
+			ids := make([]string, 0)
+			for _, jl := range jrule.Get("selector").Array() {
+				ids = append(ids, jl.Get("id").String())
+			}
+			rule["type"] = t
+			rule["selector_ids"] = ids
+		case PeerTypeSecurityGroup:
+			rule["type"] = t
+			rule["security_group_id"] = jrule.Get("security_group_id").String()
+		default:
+			eips := make([]string, 0)
+			for _, je := range jrule.Get("except_ip_block").Array() {
+				eips = append(eips, je.String())
+			}
+			rule["type"] = PeerTypeIPBlock
+			rule["ip_block"] = jrule.Get("ip_block").String()
+			rule["except_ip_block"] = eips
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// PeerRuleInputKey returns a stable key of a rule input, equal for inputs allowing the same traffic
+// however ip blocks, ports and lists are written.
+func PeerRuleInputKey(input map[string]interface{}) string {
+	parts := make([]string, 0)
+	t, _ := input["type"].(string)
+	if t == "" {
+		t = PeerTypeIPBlock
+	}
+	parts = append(parts, t)
+	switch t {
+	case PeerTypeSelector:
+		parts = append(parts, strings.Join(sortedStrings(input["selector_ids"]), ","))
+	case PeerTypeSecurityGroup:
+		sg, _ := input["security_group_id"].(string)
+		parts = append(parts, sg)
+	default:
+		block, _ := input["ip_block"].(string)
+		parts = append(parts, normalizedIPBlock(block))
+		excepts := make([]string, 0)
+		for _, e := range inputStrings(input["except_ip_block"]) {
+			excepts = append(excepts, normalizedIPBlock(e))
+		}
+		sort.Strings(excepts)
+		parts = append(parts, strings.Join(excepts, ","))
+	}
+	parts = append(parts, inputPortsKey(input["ports"]))
+	parts = append(parts, strings.Join(sortedStrings(input["services"]), ","))
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// inputPortsKey merges port entries by protocol, an entry without port allows all ports of the protocol,
+// an ICMP entry without icmp type allows all icmp types.
+func inputPortsKey(v interface{}) string {
+	specs := make(map[string][]string)
+	all := make(map[string]bool)
+	for _, port := range inputMaps(v) {
+		protocol, _ := port["protocol"].(string)
+		protocol = strings.ToUpper(protocol)
+		if alg, _ := port["alg_protocol"].(string); alg != "" {
+			protocol = otherProtocolKey(protocol, alg)
+		}
+		if icmpType, ok := port["icmp_type"]; ok && protocol == ProtocolICMP {
+			protocol = fmt.Sprintf("%s/%v", protocol, icmpType)
+			if icmpCode, ok := port["icmp_code"]; ok {
+				protocol = fmt.Sprintf("%s/%v", protocol, icmpCode)
+			}
+		}
+		spec, _ := port["port"].(string)
+		if spec == "" {
+			all[protocol] = true
+			continue
+		}
+		specs[protocol] = append(specs[protocol], spec)
+	}
+	protocols := make([]string, 0)
+	for protocol := range all {
+		if all[ProtocolICMP] && strings.HasPrefix(protocol, ProtocolICMP+"/") {
+			continue
+		}
+		protocols = append(protocols, protocol)
+	}
+	for protocol, s := range specs {
+		if all[protocol] {
+			continue
+		}
+		protocols = append(protocols, protocol+":"+mergePortSpecs(s))
+	}
+	sort.Strings(protocols)
+	return strings.Join(protocols, ";")
+}
+
+func normalizedIPBlock(block string) string {
+	if normalized, err := ip_helper.NormalizeIPBlock(block); err == nil {
+		return normalized
+	}
+	return block
+}
+
+func sortedStrings(v interface{}) []string {
+	s := inputStrings(v)
+	sort.Strings(s)
+	return s
+}
+
+// inputStrings accepts string lists built by terraform or decoded from json.
+func inputStrings(v interface{}) []string {
+	result := make([]string, 0)
+	switch l := v.(type) {
+	case []string:
+		result = append(result, l...)
+	case []interface{}:
+		for _, e := range l {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+	}
+	return result
+}
+
+// inputMaps accepts object lists built by terraform or decoded from json.
+func inputMaps(v interface{}) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0)
+	switch l := v.(type) {
+	case []map[string]interface{}:
+		result = append(result, l...)
+	case []interface{}:
+		for _, e := range l {
+			if m, ok := e.(map[string]interface{}); ok {
+				result = append(result, m)
+			}
+		}
+	}
+	return result
+}
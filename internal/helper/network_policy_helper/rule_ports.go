@@ -0,0 +1,567 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+// PortEntryModel is one everoute ports entry of a network policy rule.
+type PortEntryModel struct {
+	Protocol    types.String         `tfsdk:"protocol"`
+	Port        port_helper.PortSpec `tfsdk:"port"`
+	PortRanges  types.List           `tfsdk:"port_ranges"`
+	AlgProtocol types.String         `tfsdk:"alg_protocol"`
+	ICMPType    types.Int64          `tfsdk:"icmp_type"`
+	ICMPCode    types.Int64          `tfsdk:"icmp_code"`
+}
+
+const (
+	ProtocolTCP  = "TCP"
+	ProtocolUDP  = "UDP"
+	ProtocolICMP = "ICMP"
+	ProtocolIPIP = "IPIP"
+	ProtocolALG  = "ALG"
+)
+
+func PortEntryAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"protocol":     types.StringType,
+		"port":         port_helper.PortSpecType{},
+		"port_ranges":  types.ListType{ElemType: types.ObjectType{AttrTypes: port_helper.PortRangeAttrTypes()}},
+		"alg_protocol": types.StringType,
+		"icmp_type":    types.Int64Type,
+		"icmp_code":    types.Int64Type,
+	}
+}
+
+func portsSchema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: "network policy rule's protocol and port entries as stored by everoute, " +
+			"alternate form of tcp_enabled, tcp_ports, udp_enabled, udp_ports and icmp_enabled, " +
+			"which merge entries of the same protocol. IPIP and ALG entries, and ICMP entries of an icmp type, " +
+			"are only available in this form",
+		Optional: true,
+		Computed: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"protocol": schema.StringAttribute{
+					MarkdownDescription: "entry's protocol, valid value: TCP, UDP, ICMP, IPIP, ALG",
+					Required:            true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(ProtocolTCP, ProtocolUDP, ProtocolICMP, ProtocolIPIP, ProtocolALG),
+					},
+				},
+				"alg_protocol": schema.StringAttribute{
+					MarkdownDescription: "application layer protocol whose related connections are allowed, valid value: FTP, TFTP, " +
+						"required when protocol is ALG",
+					Optional: true,
+					Validators: []validator.String{
+						stringvalidator.OneOf("FTP", "TFTP"),
+					},
+				},
+				"icmp_type": schema.Int64Attribute{
+					MarkdownDescription: "entry's icmp type, only for ICMP, leave it unset for all icmp types",
+					Optional:            true,
+					Validators: []validator.Int64{
+						int64validator.Between(0, 255),
+					},
+				},
+				"icmp_code": schema.Int64Attribute{
+					MarkdownDescription: "entry's icmp code, only for ICMP with icmp_type, leave it unset for all codes of the type",
+					Optional:            true,
+					Validators: []validator.Int64{
+						int64validator.Between(0, 255),
+					},
+				},
+				"port": schema.StringAttribute{
+					MarkdownDescription: "entry's ports and port ranges like 80,8000-8080, only for TCP and UDP, leave it unset for all ports",
+					CustomType:          port_helper.PortSpecType{},
+					Optional:            true,
+					Computed:            true,
+					Validators: []validator.String{
+						port_helper.GetPortSpecValidator(),
+					},
+				},
+				"port_ranges": schema.ListNestedAttribute{
+					MarkdownDescription: "typed form of port, only for TCP and UDP",
+					Optional:            true,
+					Computed:            true,
+					NestedObject: schema.NestedAttributeObject{
+						Attributes: port_helper.PortRangeResourceAttributes(),
+					},
+				},
+			},
+			Validators: []validator.Object{
+				PortEntryValidator{},
+			},
+			PlanModifiers: []planmodifier.Object{
+				portEntryModifier{},
+			},
+		},
+	}
+}
+
+// ruleProtocols is the normalized tcp/udp/icmp form of a rule's ports entries,
+// Others holds sorted entries of other protocols, which have no tcp/udp/icmp form.
+type ruleProtocols struct {
+	TCPEnabled  bool
+	TCPPorts    string
+	UDPEnabled  bool
+	UDPPorts    string
+	ICMPEnabled bool
+	Others      string
+}
+
+// normalizePortEntries merges entries of the same protocol into normalized port specs,
+// an entry without port covers all ports.
+// No entries without network services mean all protocols are allowed.
+func normalizePortEntries(entries []PortEntryModel, hasServices bool) ruleProtocols {
+	if len(entries) == 0 && !hasServices {
+		return ruleProtocols{TCPEnabled: true, UDPEnabled: true, ICMPEnabled: true}
+	}
+	var result ruleProtocols
+	var tcpPorts, udpPorts, others, icmpTypes []string
+	tcpAll, udpAll := false, false
+	for _, e := range entries {
+		port := e.Port.ValueString()
+		switch protocol := strings.ToUpper(e.Protocol.ValueString()); protocol {
+		case "TCP":
+			result.TCPEnabled = true
+			tcpAll = tcpAll || port == ""
+			tcpPorts = append(tcpPorts, port)
+		case "UDP":
+			result.UDPEnabled = true
+			udpAll = udpAll || port == ""
+			udpPorts = append(udpPorts, port)
+		case "ICMP":
+			if e.ICMPType.IsNull() {
+				result.ICMPEnabled = true
+			} else {
+				icmpTypes = append(icmpTypes, icmpTypeKey(e.ICMPType, e.ICMPCode))
+			}
+		default:
+			others = append(others, otherProtocolKey(protocol, e.AlgProtocol.ValueString()))
+		}
+	}
+	// entries of an icmp type are covered by an entry of all icmp types
+	if !result.ICMPEnabled {
+		others = append(others, icmpTypes...)
+	}
+	sort.Strings(others)
+	result.Others = strings.Join(others, ";")
+	if !tcpAll {
+		result.TCPPorts = mergePortSpecs(tcpPorts)
+	}
+	if !udpAll {
+		result.UDPPorts = mergePortSpecs(udpPorts)
+	}
+	return result
+}
+
+// otherProtocolKey identifies an entry of protocol other than tcp/udp/icmp, like IPIP or ALG/FTP.
+func otherProtocolKey(protocol string, alg string) string {
+	if alg == "" {
+		return protocol
+	}
+	return protocol + "/" + strings.ToUpper(alg)
+}
+
+// icmpTypeKey identifies an ICMP entry of an icmp type, like ICMP/8 or ICMP/3/4 with code.
+func icmpTypeKey(icmpType types.Int64, icmpCode types.Int64) string {
+	key := fmt.Sprintf("%s/%d", ProtocolICMP, icmpType.ValueInt64())
+	if !icmpCode.IsNull() {
+		key += fmt.Sprintf("/%d", icmpCode.ValueInt64())
+	}
+	return key
+}
+
+// mergePortSpecs joins specs of several entries, normalized if they are not overlapped.
+func mergePortSpecs(specs []string) string {
+	merged := strings.Join(specs, ",")
+	if normalized, err := port_helper.NormalizePortSpec(merged); err == nil {
+		return normalized
+	}
+	return merged
+}
+
+// protocolsToPortEntries converts tcp/udp/icmp form to everoute ports entries.
+func protocolsToPortEntries(p ruleProtocols) []PortEntryModel {
+	entries := make([]PortEntryModel, 0)
+	if p.TCPEnabled {
+		entries = append(entries, newPortEntry("TCP", p.TCPPorts))
+	}
+	if p.UDPEnabled {
+		entries = append(entries, newPortEntry("UDP", p.UDPPorts))
+	}
+	if p.ICMPEnabled {
+		entries = append(entries, newPortEntry("ICMP", ""))
+	}
+	return entries
+}
+
+func newPortEntry(protocol string, port string) PortEntryModel {
+	entry := PortEntryModel{
+		Protocol:    types.StringValue(protocol),
+		Port:        port_helper.NewPortSpecNull(),
+		PortRanges:  port_helper.PortRangesValue(port),
+		AlgProtocol: types.StringNull(),
+		ICMPType:    types.Int64Null(),
+		ICMPCode:    types.Int64Null(),
+	}
+	if port != "" {
+		entry.Port = port_helper.NewPortSpecValue(port)
+	}
+	return entry
+}
+
+func portEntriesValue(entries []PortEntryModel) types.List {
+	values := make([]attr.Value, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, types.ObjectValueMust(PortEntryAttrTypes(), map[string]attr.Value{
+			"protocol":     e.Protocol,
+			"port":         e.Port,
+			"port_ranges":  e.PortRanges,
+			"alg_protocol": e.AlgProtocol,
+			"icmp_type":    e.ICMPType,
+			"icmp_code":    e.ICMPCode,
+		}))
+	}
+	return types.ListValueMust(types.ObjectType{AttrTypes: PortEntryAttrTypes()}, values)
+}
+
+func readGqlResultToPortEntries(input *gjson.Result) []PortEntryModel {
+	jentries := input.Array()
+	entries := make([]PortEntryModel, len(jentries))
+	for idx, je := range jentries {
+		port := je.Get("port").String()
+		if normalized, err := port_helper.NormalizePortSpec(port); err == nil {
+			port = normalized
+		}
+		entries[idx] = newPortEntry(strings.ToUpper(je.Get("protocol").String()), port)
+		// entries are read in one form, port_ranges is only planned from configured port
+		entries[idx].PortRanges = types.ListNull(types.ObjectType{AttrTypes: port_helper.PortRangeAttrTypes()})
+		if ja := je.Get("alg_protocol"); ja.Type != gjson.Null && ja.String() != "" {
+			entries[idx].AlgProtocol = types.StringValue(ja.String())
+		}
+		if jt := je.Get("icmp_type"); jt.Type != gjson.Null && jt.Exists() {
+			entries[idx].ICMPType = types.Int64Value(jt.Int())
+		}
+		if jc := je.Get("icmp_code"); jc.Type != gjson.Null && jc.Exists() {
+			entries[idx].ICMPCode = types.Int64Value(jc.Int())
+		}
+	}
+	return entries
+}
+
+// rulePortEntries returns entries of rule's ports form, falls back to tcp/udp/icmp form.
+func rulePortEntries(ctx context.Context, rule *NetworkPolicyRuleModel) ([]PortEntryModel, diag.Diagnostics) {
+	if !rule.Ports.IsNull() && !rule.Ports.IsUnknown() {
+		entries := make([]PortEntryModel, 0)
+		diags := rule.Ports.ElementsAs(ctx, &entries, false)
+		return entries, diags
+	}
+	return protocolsToPortEntries(ruleProtocols{
+		TCPEnabled:  rule.TCPEnabled.ValueBool(),
+		TCPPorts:    rule.TCPPorts.ValueString(),
+		UDPEnabled:  rule.UDPEnabled.ValueBool(),
+		UDPPorts:    rule.UDPPorts.ValueString(),
+		ICMPEnabled: rule.ICMPEnabled.ValueBool(),
+	}), nil
+}
+
+// objectPortEntries reads ports entries configured in a rule object, unknown is true if not known yet.
+func objectPortEntries(ctx context.Context, attrs map[string]attr.Value) (entries []PortEntryModel, configured bool, unknown bool, diags diag.Diagnostics) {
+	ports, _ := attrs["ports"].(types.List)
+	if ports.IsNull() {
+		return nil, false, false, diags
+	}
+	if ports.IsUnknown() {
+		return nil, true, true, diags
+	}
+	diags.Append(ports.ElementsAs(ctx, &entries, false)...)
+	for idx, e := range entries {
+		if e.Protocol.IsUnknown() || e.Port.IsUnknown() || e.PortRanges.IsUnknown() || e.ICMPType.IsUnknown() || e.ICMPCode.IsUnknown() {
+			return nil, true, true, diags
+		}
+		// port ranges is an alternate form of port
+		if e.Port.IsNull() && !e.PortRanges.IsNull() {
+			spec, d := port_helper.PortRangesToSpec(ctx, e.PortRanges)
+			diags.Append(d...)
+			entries[idx].Port = port_helper.NewPortSpecValue(spec)
+		}
+	}
+	return entries, true, false, diags
+}
+
+// objectHasServices reports if a rule object references network services, unknown is true if not known yet.
+func objectHasServices(attrs map[string]attr.Value) (hasServices bool, unknown bool) {
+	services, _ := attrs["network_service_ids"].(types.List)
+	if services.IsUnknown() {
+		return false, true
+	}
+	return !services.IsNull() && len(services.Elements()) > 0, false
+}
+
+// objectProtocols reads tcp/udp/icmp form configured in a rule object, filling defaults of unset fields.
+func objectProtocols(attrs map[string]attr.Value) (p ruleProtocols, unknown bool) {
+	bools := map[string]*bool{
+		"tcp_enabled":  &p.TCPEnabled,
+		"udp_enabled":  &p.UDPEnabled,
+		"icmp_enabled": &p.ICMPEnabled,
+	}
+	for name, target := range bools {
+		v, _ := attrs[name].(types.Bool)
+		unknown = unknown || v.IsUnknown()
+		*target = v.IsNull() || v.ValueBool()
+	}
+	strs := map[string]*string{
+		"tcp_ports": &p.TCPPorts,
+		"udp_ports": &p.UDPPorts,
+	}
+	for name, target := range strs {
+		v, _ := attrs[name].(port_helper.PortSpec)
+		unknown = unknown || v.IsUnknown()
+		*target = v.ValueString()
+	}
+	return p, unknown
+}
+
+// priorProtocols returns tcp/udp/icmp form of a rule object in prior state, from whichever form it was read in,
+// ok is false if there is no prior rule.
+func priorProtocols(ctx context.Context, state types.Object) (p ruleProtocols, ok bool, diags diag.Diagnostics) {
+	if state.IsNull() || state.IsUnknown() {
+		return p, false, diags
+	}
+	attrs := state.Attributes()
+	hasServices, servicesUnknown := objectHasServices(attrs)
+	entries, configured, unknown, diags := objectPortEntries(ctx, attrs)
+	if servicesUnknown || unknown || diags.HasError() {
+		return p, false, diags
+	}
+	if !configured {
+		protocols, unknown := objectProtocols(attrs)
+		if unknown {
+			return p, false, diags
+		}
+		entries = protocolsToPortEntries(protocols)
+	}
+	return normalizePortEntries(entries, hasServices), true, diags
+}
+
+// rulePortsModifier plans ports from tcp/udp/icmp form when ports is not configured,
+// and plans unset tcp/udp/icmp fields from ports when it is configured.
+// Prior values of the unconfigured form are kept if the prior rule normalizes to the same protocols,
+// so rules read in the other form, like after imported, plan no changes.
+// It works on the whole rule object, so siblings are read from the rule's own config in lists and sets alike.
+type rulePortsModifier struct{}
+
+func (m rulePortsModifier) Description(ctx context.Context) string {
+	return m.MarkdownDescription(ctx)
+}
+
+func (m rulePortsModifier) MarkdownDescription(_ context.Context) string {
+	return "plan ports and tcp_enabled, tcp_ports, udp_enabled, udp_ports, icmp_enabled from each other"
+}
+
+func (m rulePortsModifier) PlanModifyObject(ctx context.Context, req planmodifier.ObjectRequest, resp *planmodifier.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+	config := req.ConfigValue.Attributes()
+	plan := req.PlanValue.Attributes()
+	hasServices, servicesUnknown := objectHasServices(config)
+
+	entries, configured, unknown, diags := objectPortEntries(ctx, config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if configured {
+		resp.Diagnostics.Append(m.planProtocols(ctx, req.StateValue, config, plan, entries, hasServices, unknown || servicesUnknown)...)
+	} else {
+		resp.Diagnostics.Append(m.planPorts(ctx, req.StateValue, config, plan, hasServices, servicesUnknown)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	value, diags := types.ObjectValue(req.PlanValue.AttributeTypes(ctx), plan)
+	resp.Diagnostics.Append(diags...)
+	resp.PlanValue = value
+}
+
+func (m rulePortsModifier) planPorts(ctx context.Context, state types.Object, config map[string]attr.Value, plan map[string]attr.Value, hasServices bool, servicesUnknown bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+	p, unknown := objectProtocols(config)
+	if unknown || servicesUnknown {
+		plan["ports"] = types.ListUnknown(types.ObjectType{AttrTypes: PortEntryAttrTypes()})
+		return diags
+	}
+	entries := protocolsToPortEntries(p)
+	prior, ok, diags := priorProtocols(ctx, state)
+	if ok && prior == normalizePortEntries(entries, hasServices) {
+		plan["ports"] = state.Attributes()["ports"]
+		return diags
+	}
+	plan["ports"] = portEntriesValue(entries)
+	return diags
+}
+
+func (m rulePortsModifier) planProtocols(ctx context.Context, state types.Object, config map[string]attr.Value, plan map[string]attr.Value, entries []PortEntryModel, hasServices bool, unknown bool) diag.Diagnostics {
+	p := normalizePortEntries(entries, hasServices)
+	prior, ok, diags := priorProtocols(ctx, state)
+	if !unknown && ok && prior == p {
+		for _, name := range []string{"tcp_enabled", "tcp_ports", "udp_enabled", "udp_ports", "icmp_enabled"} {
+			if config[name].IsNull() {
+				plan[name] = state.Attributes()[name]
+			}
+		}
+		return diags
+	}
+	bools := map[string]bool{
+		"tcp_enabled":  p.TCPEnabled,
+		"udp_enabled":  p.UDPEnabled,
+		"icmp_enabled": p.ICMPEnabled,
+	}
+	for name, v := range bools {
+		if !config[name].IsNull() {
+			continue
+		}
+		if unknown {
+			plan[name] = types.BoolUnknown()
+		} else {
+			plan[name] = types.BoolValue(v)
+		}
+	}
+	strs := map[string]string{
+		"tcp_ports": p.TCPPorts,
+		"udp_ports": p.UDPPorts,
+	}
+	for name, v := range strs {
+		if !config[name].IsNull() {
+			continue
+		}
+		if unknown {
+			plan[name] = port_helper.NewPortSpecUnknown()
+		} else {
+			plan[name] = port_helper.NewPortSpecValue(v)
+		}
+	}
+	return diags
+}
+
+// portEntryModifier plans port and port_ranges of a ports entry from each other, whichever is not configured,
+// keeping prior port_ranges of the same port, which is null for entries as read.
+type portEntryModifier struct{}
+
+func (m portEntryModifier) Description(ctx context.Context) string {
+	return m.MarkdownDescription(ctx)
+}
+
+func (m portEntryModifier) MarkdownDescription(_ context.Context) string {
+	return "plan port from port_ranges or port_ranges from port when it is not configured"
+}
+
+func (m portEntryModifier) PlanModifyObject(ctx context.Context, req planmodifier.ObjectRequest, resp *planmodifier.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() || req.PlanValue.IsNull() || req.PlanValue.IsUnknown() {
+		return
+	}
+	config := req.ConfigValue.Attributes()
+	plan := req.PlanValue.Attributes()
+	port, _ := config["port"].(port_helper.PortSpec)
+	ranges, _ := config["port_ranges"].(types.List)
+	if port.IsNull() {
+		switch {
+		case ranges.IsUnknown():
+			plan["port"] = port_helper.NewPortSpecUnknown()
+		case ranges.IsNull():
+			plan["port"] = port_helper.NewPortSpecNull()
+		default:
+			spec, diags := port_helper.PortRangesToSpec(ctx, ranges)
+			resp.Diagnostics.Append(diags...)
+			plan["port"] = port_helper.NewPortSpecValue(spec)
+		}
+	}
+	if ranges.IsNull() {
+		priorPort, priorRanges, ok := priorEntryPorts(req.StateValue)
+		if ok && !port.IsUnknown() && priorPort.Equal(port) {
+			plan["port_ranges"] = priorRanges
+		} else if port.IsUnknown() {
+			plan["port_ranges"] = types.ListUnknown(types.ObjectType{AttrTypes: port_helper.PortRangeAttrTypes()})
+		} else {
+			plan["port_ranges"] = port_helper.PortRangesValue(port.ValueString())
+		}
+	}
+	value, diags := types.ObjectValue(req.PlanValue.AttributeTypes(ctx), plan)
+	resp.Diagnostics.Append(diags...)
+	resp.PlanValue = value
+}
+
+// priorEntryPorts returns port and port_ranges of a ports entry in prior state, ok is false if there is no prior entry.
+func priorEntryPorts(state types.Object) (port port_helper.PortSpec, ranges attr.Value, ok bool) {
+	if state.IsNull() || state.IsUnknown() {
+		return port, nil, false
+	}
+	port, _ = state.Attributes()["port"].(port_helper.PortSpec)
+	return port, state.Attributes()["port_ranges"], true
+}
+
+var _ validator.Object = PortEntryValidator{}
+
+type PortEntryValidator struct{}
+
+func (v PortEntryValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v PortEntryValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate ports entry, port and port_ranges are exclusive and only for TCP and UDP, alg_protocol is only for ALG, " +
+		"icmp_type and icmp_code are only for ICMP"
+}
+
+func (v PortEntryValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	attrs := req.ConfigValue.Attributes()
+	portSet := !attrs["port"].IsNull()
+	rangesSet := !attrs["port_ranges"].IsNull()
+	algSet := !attrs["alg_protocol"].IsNull()
+	if portSet && rangesSet {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports entry", "port and port_ranges cannot be both configured")
+	}
+	protocol, _ := attrs["protocol"].(types.String)
+	if protocol.IsUnknown() {
+		return
+	}
+	p := protocol.ValueString()
+	if p != ProtocolTCP && p != ProtocolUDP && (portSet || rangesSet) {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports entry", fmt.Sprintf("port and port_ranges are not allowed when protocol is %s", p))
+	}
+	if p == ProtocolALG && !algSet && !attrs["alg_protocol"].IsUnknown() {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports entry", "alg_protocol is required when protocol is ALG")
+	}
+	if p != ProtocolALG && algSet {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports entry", fmt.Sprintf("alg_protocol is not allowed when protocol is %s", p))
+	}
+	typeSet := !attrs["icmp_type"].IsNull()
+	codeSet := !attrs["icmp_code"].IsNull()
+	if p != ProtocolICMP && (typeSet || codeSet) {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports entry", fmt.Sprintf("icmp_type and icmp_code are not allowed when protocol is %s", p))
+	}
+	if codeSet && !typeSet && !attrs["icmp_type"].IsUnknown() {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports entry", "icmp_type is required when icmp_code is configured")
+	}
+}
@@ -0,0 +1,115 @@
+package network_policy_helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	tcpRuleResult   = `{"ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}, {"protocol": "TCP", "port": "80"}]}`
+	ipipRuleResult  = `{"ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}, {"protocol": "IPIP"}]}`
+	emptyRuleResult = `{"ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": []}`
+)
+
+func TestReadGqlResultToNetworkPolicyRuleModelInOneForm(t *testing.T) {
+	jrule := gjson.Parse(tcpRuleResult)
+	rule := ReadGqlResultToNetworkPolicyRuleModel(&jrule)
+	if !rule.Ports.IsNull() {
+		t.Errorf("ports = %v, want null for rules of tcp/udp/icmp form", rule.Ports)
+	}
+	if !rule.TCPEnabled.ValueBool() || rule.TCPPorts.ValueString() != "22,80" || rule.UDPEnabled.ValueBool() || rule.ICMPEnabled.ValueBool() {
+		t.Errorf("tcp/udp/icmp form = %v %v %v %v, want tcp 22,80 only", rule.TCPEnabled, rule.TCPPorts, rule.UDPEnabled, rule.ICMPEnabled)
+	}
+
+	for name, result := range map[string]string{"ipip": ipipRuleResult, "all protocols": emptyRuleResult} {
+		jrule := gjson.Parse(result)
+		rule := ReadGqlResultToNetworkPolicyRuleModel(&jrule)
+		if rule.Ports.IsNull() {
+			t.Errorf("%s: ports is null, want entries as read", name)
+		}
+		if !rule.TCPEnabled.IsNull() || !rule.TCPPorts.IsNull() || !rule.UDPEnabled.IsNull() || !rule.UDPPorts.IsNull() || !rule.ICMPEnabled.IsNull() {
+			t.Errorf("%s: tcp/udp/icmp form is read together with ports", name)
+		}
+		entries := make([]PortEntryModel, 0)
+		rule.Ports.ElementsAs(context.Background(), &entries, false)
+		for _, e := range entries {
+			if !e.PortRanges.IsNull() {
+				t.Errorf("%s: port_ranges of %s entry = %v, want only port read", name, e.Protocol, e.PortRanges)
+			}
+		}
+	}
+}
+
+func TestRulePortsModifierKeepsFormAsRead(t *testing.T) {
+	ctx := context.Background()
+	attrTypes := NetworkPolicyRuleSchema().Type().(types.ObjectType).AttrTypes
+	for name, result := range map[string]string{"tcp": tcpRuleResult, "ipip": ipipRuleResult, "all protocols": emptyRuleResult} {
+		t.Run(name, func(t *testing.T) {
+			jrule := gjson.Parse(result)
+			rule := ReadGqlResultToNetworkPolicyRuleModel(&jrule)
+			state, diags := types.ObjectValueFrom(ctx, attrTypes, rule)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			// config generated after imported carries state, unset attributes are planned by defaults or unknown
+			attrs := make(map[string]attr.Value)
+			for k, v := range state.Attributes() {
+				attrs[k] = v
+			}
+			if rule.Ports.IsNull() {
+				attrs["ports"] = types.ListUnknown(types.ObjectType{AttrTypes: PortEntryAttrTypes()})
+			} else {
+				attrs["tcp_enabled"] = types.BoolValue(true)
+				attrs["tcp_ports"] = port_helper.NewPortSpecValue("")
+				attrs["udp_enabled"] = types.BoolValue(true)
+				attrs["udp_ports"] = port_helper.NewPortSpecValue("")
+				attrs["icmp_enabled"] = types.BoolValue(true)
+			}
+			plan := types.ObjectValueMust(attrTypes, attrs)
+			req := planmodifier.ObjectRequest{ConfigValue: state, StateValue: state, PlanValue: plan}
+			resp := &planmodifier.ObjectResponse{PlanValue: plan}
+			rulePortsModifier{}.PlanModifyObject(ctx, req, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+			if !resp.PlanValue.Equal(state) {
+				t.Errorf("plan = %v, want state %v", resp.PlanValue, state)
+			}
+		})
+	}
+}
+
+func TestPortEntryModifierKeepsRangesAsRead(t *testing.T) {
+	ctx := context.Background()
+	entry := newPortEntry(ProtocolTCP, "22")
+	entry.PortRanges = types.ListNull(types.ObjectType{AttrTypes: port_helper.PortRangeAttrTypes()})
+	state, diags := types.ObjectValueFrom(ctx, PortEntryAttrTypes(), entry)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	attrs := state.Attributes()
+	attrs["port_ranges"] = types.ListUnknown(types.ObjectType{AttrTypes: port_helper.PortRangeAttrTypes()})
+	plan := types.ObjectValueMust(PortEntryAttrTypes(), attrs)
+
+	for name, c := range map[string]struct {
+		state      types.Object
+		wantRanges bool
+	}{
+		"as read": {state, false},
+		"created": {types.ObjectNull(PortEntryAttrTypes()), true},
+	} {
+		req := planmodifier.ObjectRequest{ConfigValue: state, StateValue: c.state, PlanValue: plan}
+		resp := &planmodifier.ObjectResponse{PlanValue: plan}
+		portEntryModifier{}.PlanModifyObject(ctx, req, resp)
+		ranges := resp.PlanValue.Attributes()["port_ranges"]
+		if ranges.IsUnknown() || ranges.IsNull() == c.wantRanges {
+			t.Errorf("%s: port_ranges = %v, want planned %v", name, ranges, c.wantRanges)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/vm_helper"
+)
+
+// vmPeerIPBlocks resolves the vm referenced by id or name to ip blocks of its ips, sorted and deduplicated.
+func vmPeerIPBlocks(api *apiclient.Cloudtower, ref string) ([]string, diag.Diagnostics) {
+	vm, diags := vm_helper.GetVmByIdOrName(api, ref)
+	if diags.HasError() {
+		return nil, diags
+	}
+	if vm == nil {
+		diags.AddError("Failed to resolve network policy peer", fmt.Sprintf("vm %s not found", ref))
+		return nil, diags
+	}
+	ips := ""
+	if vm.Ips != nil {
+		ips = *vm.Ips
+	}
+	seen := make(map[string]bool)
+	blocks := make([]string, 0)
+	for _, ip := range vm_helper.SplitVmIps(ips) {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil || seen[addr.String()] {
+			continue
+		}
+		seen[addr.String()] = true
+		blocks = append(blocks, addr.String())
+	}
+	if len(blocks) == 0 {
+		diags.AddError(
+			"Failed to resolve network policy peer",
+			fmt.Sprintf("vm %s reports no ip, make sure vm tools is running in the vm", ref),
+		)
+		return nil, diags
+	}
+	sort.Strings(blocks)
+	return blocks, diags
+}
+
+// expandVmPeerRule returns ip block rules allowing the same traffic as a vm peer rule, one for each ip block.
+func expandVmPeerRule(peer *PeerRuleModel, blocks []string) []PeerRuleModel {
+	rules := make([]PeerRuleModel, 0, len(blocks))
+	for _, block := range blocks {
+		rule := *peer
+		rule.Type = types.StringValue(PeerTypeIPBlock)
+		rule.IPBlock = ip_helper.NewIPBlockValue(block)
+		rule.ExceptIPBlock = ip_helper.IPBlockListValue(nil)
+		rule.Vm = types.StringNull()
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// CollapseVmPeerRules replaces ip block rules as read with the prior vm peer rules they were resolved from,
+// at the position of the first of them. A vm peer rule is left out if rules of its vm's current ips are not
+// all found, so that changed ips of the vm are planned as drift.
+func CollapseVmPeerRules(ctx context.Context, api *apiclient.Cloudtower, rules []PeerRuleModel, prior []PeerRuleModel) ([]PeerRuleModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	analyzed := make([]*analyzedRule, len(rules))
+	for i := range rules {
+		analyzed[i] = analyzePeerRule(ctx, &rules[i])
+	}
+	used := make([]bool, len(rules))
+	collapsed := make(map[int]PeerRuleModel)
+	for i := range prior {
+		if prior[i].Type.ValueString() != PeerTypeVM || !isKnown(prior[i].Vm) {
+			continue
+		}
+		blocks, d := vmPeerIPBlocks(api, prior[i].Vm.ValueString())
+		if d.HasError() {
+			// the rule is planned to be applied again, which reports the error if it persists
+			for _, e := range d.Errors() {
+				diags.AddWarning(e.Summary(), e.Detail())
+			}
+			continue
+		}
+		matches := make([]int, 0)
+		for _, expected := range expandVmPeerRule(&prior[i], blocks) {
+			expected := expected
+			e := analyzePeerRule(ctx, &expected)
+			match := -1
+			for j, r := range analyzed {
+				if !used[j] && r != nil && e != nil && r.covers(e) && e.covers(r) {
+					match = j
+					break
+				}
+			}
+			if match < 0 {
+				break
+			}
+			used[match] = true
+			matches = append(matches, match)
+		}
+		if len(matches) < len(blocks) {
+			for _, j := range matches {
+				used[j] = false
+			}
+			continue
+		}
+		sort.Ints(matches)
+		collapsed[matches[0]] = prior[i]
+	}
+	result := make([]PeerRuleModel, 0, len(rules))
+	for j := range rules {
+		if rule, ok := collapsed[j]; ok {
+			result = append(result, rule)
+		} else if !used[j] {
+			result = append(result, rules[j])
+		}
+	}
+	return result, diags
+}
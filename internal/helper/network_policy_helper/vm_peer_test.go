@@ -0,0 +1,129 @@
+package network_policy_helper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute/fake_cloudtower"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/tidwall/gjson"
+)
+
+func sshRule(block string) PeerRuleModel {
+	return *testIPBlockRule(block, []PortEntryModel{newPortEntry(ProtocolTCP, "22")})
+}
+
+func testVmRule(vm string) PeerRuleModel {
+	rule := sshRule("10.0.0.1")
+	rule.Type = types.StringValue(PeerTypeVM)
+	rule.IPBlock = ip_helper.NewIPBlockNull()
+	rule.Vm = types.StringValue(vm)
+	return rule
+}
+
+func TestCollapseVmPeerRules(t *testing.T) {
+	cases := []struct {
+		name         string
+		vmIps        map[string]string
+		rules        []PeerRuleModel
+		prior        []PeerRuleModel
+		want         []string
+		wantWarnings int
+	}{
+		{
+			name:  "all ips found",
+			vmIps: map[string]string{"vm-1": "10.0.0.2,10.0.0.1"},
+			rules: []PeerRuleModel{sshRule("10.0.0.9"), sshRule("10.0.0.2"), sshRule("10.0.0.1")},
+			prior: []PeerRuleModel{testVmRule("vm-1"), sshRule("10.0.0.9")},
+			want:  []string{"10.0.0.9", "vm-1"},
+		},
+		{
+			name:  "one ip changed",
+			vmIps: map[string]string{"vm-1": "10.0.0.1,10.0.0.3"},
+			rules: []PeerRuleModel{sshRule("10.0.0.1"), sshRule("10.0.0.2")},
+			prior: []PeerRuleModel{testVmRule("vm-1")},
+			want:  []string{"10.0.0.1", "10.0.0.2"},
+		},
+		{
+			name:  "explicit rule identical to an expanded one",
+			vmIps: map[string]string{"vm-1": "10.0.0.1"},
+			rules: []PeerRuleModel{sshRule("10.0.0.1"), sshRule("10.0.0.1")},
+			prior: []PeerRuleModel{sshRule("10.0.0.1"), testVmRule("vm-1")},
+			want:  []string{"vm-1", "10.0.0.1"},
+		},
+		{
+			name:  "expanded rule shared by vms",
+			vmIps: map[string]string{"vm-1": "10.0.0.1", "vm-2": "10.0.0.1"},
+			rules: []PeerRuleModel{sshRule("10.0.0.1")},
+			prior: []PeerRuleModel{testVmRule("vm-1"), testVmRule("vm-2")},
+			want:  []string{"vm-1"},
+		},
+		{
+			name:         "vm not found",
+			vmIps:        map[string]string{},
+			rules:        []PeerRuleModel{sshRule("10.0.0.1")},
+			prior:        []PeerRuleModel{testVmRule("vm-1")},
+			want:         []string{"10.0.0.1"},
+			wantWarnings: 1,
+		},
+		{
+			name:         "vm reports no ip",
+			vmIps:        map[string]string{"vm-1": ""},
+			rules:        []PeerRuleModel{sshRule("10.0.0.1")},
+			prior:        []PeerRuleModel{testVmRule("vm-1")},
+			want:         []string{"10.0.0.1"},
+			wantWarnings: 1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := fake_cloudtower.NewServer(t)
+			server.HandleApi("get-vms", func(body gjson.Result) (interface{}, error) {
+				vms := []interface{}{}
+				for _, or := range body.Get("where.OR").Array() {
+					ref := or.Get("id").String() + or.Get("name").String()
+					if ips, ok := c.vmIps[ref]; ok {
+						vms = append(vms, map[string]interface{}{"id": ref, "name": ref, "ips": ips})
+						break
+					}
+				}
+				return vms, nil
+			})
+			result, diags := CollapseVmPeerRules(context.Background(), server.Client(t).Api, c.rules, c.prior)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if diags.WarningsCount() != c.wantWarnings {
+				t.Errorf("got %d warnings, want %d: %v", diags.WarningsCount(), c.wantWarnings, diags)
+			}
+			got := make([]string, 0, len(result))
+			for _, rule := range result {
+				if rule.Type.ValueString() == PeerTypeVM {
+					got = append(got, rule.Vm.ValueString())
+				} else {
+					got = append(got, rule.IPBlock.ValueString())
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.want) {
+				t.Errorf("rules = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCollapseVmPeerRulesOfLookupError(t *testing.T) {
+	server := fake_cloudtower.NewServer(t)
+	server.HandleApi("get-vms", func(body gjson.Result) (interface{}, error) {
+		return nil, fmt.Errorf("cloudtower is unavailable")
+	})
+	rules := []PeerRuleModel{sshRule("10.0.0.1")}
+	result, diags := CollapseVmPeerRules(context.Background(), server.Client(t).Api, rules, []PeerRuleModel{testVmRule("vm-1")})
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("diagnostics = %v, want the lookup error as a warning only", diags)
+	}
+	if len(result) != 1 || result[0].IPBlock.ValueString() != "10.0.0.1" {
+		t.Errorf("rules = %v, want the rule as read", result)
+	}
+}
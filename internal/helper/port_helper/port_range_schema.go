@@ -0,0 +1,80 @@
+package port_helper
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// PortRangeModel is the typed form of one port or port range, From equals To for a single port.
+type PortRangeModel struct {
+	From types.Int64 `tfsdk:"from"`
+	To   types.Int64 `tfsdk:"to"`
+}
+
+func PortRangeAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"from": types.Int64Type,
+		"to":   types.Int64Type,
+	}
+}
+
+// PortRangeResourceAttributes returns attributes of a typed port range.
+func PortRangeResourceAttributes() map[string]schema.Attribute {
+	return map[string]schema.Attribute{
+		"from": schema.Int64Attribute{
+			MarkdownDescription: "first port of the range",
+			Required:            true,
+			Validators: []validator.Int64{
+				int64validator.Between(MinPort, MaxPort),
+			},
+		},
+		"to": schema.Int64Attribute{
+			MarkdownDescription: "last port of the range, same as from for a single port",
+			Required:            true,
+			Validators: []validator.Int64{
+				int64validator.Between(MinPort, MaxPort),
+			},
+		},
+	}
+}
+
+// PortRangesValue converts spec to typed port ranges, returns null list for empty or invalid spec.
+func PortRangesValue(spec string) types.List {
+	elemType := types.ObjectType{AttrTypes: PortRangeAttrTypes()}
+	ranges, err := ParsePortSpec(spec)
+	if err != nil || len(ranges) == 0 {
+		return types.ListNull(elemType)
+	}
+	values := make([]attr.Value, len(ranges))
+	for i, r := range ranges {
+		values[i] = types.ObjectValueMust(PortRangeAttrTypes(), map[string]attr.Value{
+			"from": types.Int64Value(r.From),
+			"to":   types.Int64Value(r.To),
+		})
+	}
+	return types.ListValueMust(elemType, values)
+}
+
+// PortRangesToSpec converts typed port ranges to a port spec, ranges are validated as the spec form.
+func PortRangesToSpec(ctx context.Context, list types.List) (string, diag.Diagnostics) {
+	models := make([]PortRangeModel, 0)
+	diags := list.ElementsAs(ctx, &models, false)
+	if diags.HasError() {
+		return "", diags
+	}
+	ranges := make([]PortRange, len(models))
+	for i, m := range models {
+		ranges[i] = PortRange{From: m.From.ValueInt64(), To: m.To.ValueInt64()}
+	}
+	spec, err := NormalizePortSpec(FormatPortSpec(ranges))
+	if err != nil {
+		diags.AddError("invalid port ranges", err.Error())
+	}
+	return spec, diags
+}
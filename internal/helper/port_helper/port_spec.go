@@ -0,0 +1,116 @@
+package port_helper
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	MinPort = 1
+	MaxPort = 65535
+)
+
+// PortRange is an inclusive range of ports, From equals To for a single port.
+type PortRange struct {
+	From int64
+	To   int64
+}
+
+func (r PortRange) String() string {
+	if r.From == r.To {
+		return strconv.FormatInt(r.From, 10)
+	}
+	return fmt.Sprintf("%d-%d", r.From, r.To)
+}
+
+// ParsePortSpec parses comma separated ports and port ranges like "80, 443, 8000-8080",
+// ranges are sorted and must not overlap. Empty spec means all ports and returns no range.
+func ParsePortSpec(spec string) ([]PortRange, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+	ranges := make([]PortRange, 0)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return nil, fmt.Errorf("empty port in %q", spec)
+		}
+		from, to, isRange := strings.Cut(item, "-")
+		r := PortRange{}
+		var err error
+		if r.From, err = parsePort(from); err != nil {
+			return nil, err
+		}
+		r.To = r.From
+		if isRange {
+			if r.To, err = parsePort(to); err != nil {
+				return nil, err
+			}
+			if r.From > r.To {
+				return nil, fmt.Errorf("invalid port range %q, start is greater than end", item)
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].From < ranges[j].From
+	})
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].From <= ranges[i-1].To {
+			return nil, fmt.Errorf("port %s overlaps with %s", ranges[i], ranges[i-1])
+		}
+	}
+	return ranges, nil
+}
+
+func parsePort(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	port, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid port %q, should be a number or a range like 8000-8080", s)
+	}
+	if port < MinPort || port > MaxPort {
+		return 0, fmt.Errorf("invalid port %d, should be between %d and %d", port, MinPort, MaxPort)
+	}
+	return port, nil
+}
+
+// MergePortRanges sorts ranges and joins the overlapping or adjacent ones, like 1-100 and 101-200 to 1-200.
+func MergePortRanges(ranges []PortRange) []PortRange {
+	sorted := make([]PortRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].From < sorted[j].From
+	})
+	merged := make([]PortRange, 0, len(sorted))
+	for _, r := range sorted {
+		if last := len(merged) - 1; last >= 0 && r.From <= merged[last].To+1 {
+			if r.To > merged[last].To {
+				merged[last].To = r.To
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// FormatPortSpec formats ranges to the canonical port spec everoute stores.
+func FormatPortSpec(ranges []PortRange) string {
+	items := make([]string, len(ranges))
+	for i, r := range ranges {
+		items[i] = r.String()
+	}
+	return strings.Join(items, ",")
+}
+
+// NormalizePortSpec returns canonical form of spec, sorted and without whitespaces.
+func NormalizePortSpec(spec string) (string, error) {
+	ranges, err := ParsePortSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	return FormatPortSpec(ranges), nil
+}
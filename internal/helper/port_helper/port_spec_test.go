@@ -0,0 +1,97 @@
+package port_helper
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    []PortRange
+		wantErr bool
+	}{
+		{spec: "", want: nil},
+		{spec: "  ", want: nil},
+		{spec: "1", want: []PortRange{{1, 1}}},
+		{spec: "65535", want: []PortRange{{65535, 65535}}},
+		{spec: "1-65535", want: []PortRange{{1, 65535}}},
+		{spec: "0", wantErr: true},
+		{spec: "65536", wantErr: true},
+		{spec: "0-80", wantErr: true},
+		{spec: "80-65536", wantErr: true},
+		{spec: "443, 80 ,8000 - 8080", want: []PortRange{{80, 80}, {443, 443}, {8000, 8080}}},
+		{spec: "80;443", wantErr: true},
+		{spec: "80,", wantErr: true},
+		{spec: "80-", wantErr: true},
+		{spec: "8080-8000", wantErr: true},
+		{spec: "80,80", wantErr: true},
+		{spec: "8000-8080,8080", wantErr: true},
+		{spec: "8000-8080,7000-8000", wantErr: true},
+		{spec: "8081,8000-8080", want: []PortRange{{8000, 8080}, {8081, 8081}}},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			got, err := ParsePortSpec(c.spec)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("ParsePortSpec(%q) error = %v, want error %v", c.spec, err, c.wantErr)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.want) {
+				t.Errorf("ParsePortSpec(%q) = %v, want %v", c.spec, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMergePortRanges(t *testing.T) {
+	cases := []struct {
+		name   string
+		ranges []PortRange
+		want   []PortRange
+	}{
+		{"empty", nil, []PortRange{}},
+		{"unsorted", []PortRange{{443, 443}, {80, 80}}, []PortRange{{80, 80}, {443, 443}}},
+		{"overlapping", []PortRange{{1, 100}, {50, 150}}, []PortRange{{1, 150}}},
+		{"adjacent", []PortRange{{101, 200}, {1, 100}}, []PortRange{{1, 200}}},
+		{"contained", []PortRange{{1, 1000}, {80, 80}}, []PortRange{{1, 1000}}},
+		{"gap", []PortRange{{1, 100}, {102, 200}}, []PortRange{{1, 100}, {102, 200}}},
+		{"bounds", []PortRange{{MaxPort, MaxPort}, {MinPort, MaxPort - 1}}, []PortRange{{MinPort, MaxPort}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			input := append([]PortRange(nil), c.ranges...)
+			if got := MergePortRanges(c.ranges); fmt.Sprint(got) != fmt.Sprint(c.want) {
+				t.Errorf("MergePortRanges(%v) = %v, want %v", c.ranges, got, c.want)
+			}
+			if fmt.Sprint(c.ranges) != fmt.Sprint(input) {
+				t.Errorf("MergePortRanges modified its input to %v", c.ranges)
+			}
+		})
+	}
+}
+
+func TestNormalizePortSpec(t *testing.T) {
+	cases := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "", want: ""},
+		{spec: " 443 , 80 ", want: "80,443"},
+		{spec: "8000 - 8080,22", want: "22,8000-8080"},
+		{spec: "1-1", want: "1"},
+		{spec: "80;443", wantErr: true},
+		{spec: "1-100,50", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.spec, func(t *testing.T) {
+			got, err := NormalizePortSpec(c.spec)
+			if (err != nil) != c.wantErr {
+				t.Fatalf("NormalizePortSpec(%q) error = %v, want error %v", c.spec, err, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("NormalizePortSpec(%q) = %q, want %q", c.spec, got, c.want)
+			}
+		})
+	}
+}
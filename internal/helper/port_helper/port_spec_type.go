@@ -0,0 +1,107 @@
+package port_helper
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var _ basetypes.StringTypable = PortSpecType{}
+
+// PortSpecType is a string type holding a port spec, specs of the same ports are semantically equal.
+type PortSpecType struct {
+	basetypes.StringType
+}
+
+func (t PortSpecType) String() string {
+	return "port_helper.PortSpecType"
+}
+
+func (t PortSpecType) Equal(o attr.Type) bool {
+	other, ok := o.(PortSpecType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t PortSpecType) ValueType(ctx context.Context) attr.Value {
+	return PortSpec{}
+}
+
+func (t PortSpecType) ValueFromString(ctx context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return PortSpec{StringValue: in}, nil
+}
+
+func (t PortSpecType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+	return stringValuable, nil
+}
+
+var _ basetypes.StringValuableWithSemanticEquals = PortSpec{}
+
+// PortSpec is a value of PortSpecType.
+type PortSpec struct {
+	basetypes.StringValue
+}
+
+func NewPortSpecValue(value string) PortSpec {
+	return PortSpec{StringValue: basetypes.NewStringValue(value)}
+}
+
+func NewPortSpecNull() PortSpec {
+	return PortSpec{StringValue: basetypes.NewStringNull()}
+}
+
+func NewPortSpecUnknown() PortSpec {
+	return PortSpec{StringValue: basetypes.NewStringUnknown()}
+}
+
+func (v PortSpec) Type(ctx context.Context) attr.Type {
+	return PortSpecType{}
+}
+
+func (v PortSpec) Equal(o attr.Value) bool {
+	other, ok := o.(PortSpec)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+// StringSemanticEquals treats specs of the same ports as equal regardless of ordering and whitespaces.
+func (v PortSpec) StringSemanticEquals(ctx context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	newValue, ok := newValuable.(PortSpec)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got value type %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+	prior, err := NormalizePortSpec(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	current, err := NormalizePortSpec(newValue.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	return prior == current, diags
+}
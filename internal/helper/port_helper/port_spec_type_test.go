@@ -0,0 +1,37 @@
+package port_helper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestPortSpecStringSemanticEquals(t *testing.T) {
+	cases := []struct {
+		prior   string
+		current string
+		want    bool
+	}{
+		{"80,443", "443, 80", true},
+		{"8000-8080", " 8000 - 8080 ", true},
+		{"", "", true},
+		{"80", "81", false},
+		{"80,443", "80", false},
+		{"80;443", "80;443", false},
+	}
+	for _, c := range cases {
+		t.Run(c.prior+" "+c.current, func(t *testing.T) {
+			equal, diags := NewPortSpecValue(c.prior).StringSemanticEquals(context.Background(), NewPortSpecValue(c.current))
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if equal != c.want {
+				t.Errorf("%q equals %q = %v, want %v", c.prior, c.current, equal, c.want)
+			}
+		})
+	}
+	if _, diags := NewPortSpecValue("80").StringSemanticEquals(context.Background(), types.StringValue("80")); !diags.HasError() {
+		t.Error("comparing to a plain string is not reported")
+	}
+}
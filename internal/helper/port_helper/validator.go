@@ -0,0 +1,32 @@
+package port_helper
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var _ validator.String = PortSpecValidator{}
+
+func GetPortSpecValidator() validator.String {
+	return PortSpecValidator{}
+}
+
+type PortSpecValidator struct{}
+
+func (v PortSpecValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v PortSpecValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate ports and port ranges are between 1 and 65535 and not overlapped"
+}
+
+func (v PortSpecValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := ParsePortSpec(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(req.Path, "invalid ports", err.Error())
+	}
+}
@@ -2,11 +2,18 @@ package vm_helper
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	apiclient "github.com/smartxworks/cloudtower-go-sdk/v2/client"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
 	"github.com/tidwall/gjson"
 )
@@ -56,22 +63,111 @@ func ReadSdkVmToModel(input *models.VM, output *VmModel) {
 	output.Id = types.StringValue(*input.ID)
 	output.Name = types.StringValue(*input.Name)
 	output.Status = types.StringValue(string(*input.Status))
-	ips := strings.Split(*input.Ips, ",")
-	aips := make([]types.String, len(ips))
-	for _, ip := range ips {
-		aips = append(aips, types.StringValue(ip))
-	}
-	output.Ips, _ = types.ListValueFrom(context.Background(), types.StringType, aips)
+	output.Ips, _ = types.ListValueFrom(context.Background(), types.StringType, SplitVmIps(*input.Ips))
 }
 
 func ReadGJsonVmToModel(input *gjson.Result, output *VmModel) {
 	output.Id = types.StringValue(input.Get("id").String())
 	output.Name = types.StringValue(input.Get("name").String())
 	output.Status = types.StringValue(input.Get("status").String())
-	ips := strings.Split(input.Get("ips").String(), ",")
-	aips := make([]types.String, len(ips))
-	for _, ip := range ips {
-		aips = append(aips, types.StringValue(ip))
+	output.Ips, _ = types.ListValueFrom(context.Background(), types.StringType, SplitVmIps(input.Get("ips").String()))
+}
+
+// SplitVmIps splits comma separated ips reported by vm tools, empty ips are ignored.
+func SplitVmIps(ips string) []string {
+	result := make([]string, 0)
+	for _, ip := range strings.Split(ips, ",") {
+		ip = strings.TrimSpace(ip)
+		if ip != "" {
+			result = append(result, ip)
+		}
+	}
+	return result
+}
+
+func VmResourceSchema() rschema.Attribute {
+	return rschema.SingleNestedAttribute{
+		MarkdownDescription: "cloudtower's vm schema",
+		Computed:            true,
+		Attributes:          VmResourceAttributes(),
+		PlanModifiers: []planmodifier.Object{
+			objectplanmodifier.UseStateForUnknown(),
+		},
+	}
+}
+
+func VmResourceAttributes() map[string]rschema.Attribute {
+	return map[string]rschema.Attribute{
+		"id": rschema.StringAttribute{
+			MarkdownDescription: "vm's id",
+			Computed:            true,
+		},
+		"name": rschema.StringAttribute{
+			MarkdownDescription: "vm's name",
+			Computed:            true,
+		},
+		"status": rschema.StringAttribute{
+			MarkdownDescription: "vm's status",
+			Computed:            true,
+		},
+		"ips": rschema.ListAttribute{
+			MarkdownDescription: "vm's ips",
+			Computed:            true,
+			ElementType:         types.StringType,
+		},
+	}
+}
+
+// GetVmById returns nil vm without error when vm not found.
+func GetVmById(api *apiclient.Cloudtower, id string) (*models.VM, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	gvp := vm.NewGetVmsParams()
+	gvp.RequestBody = &models.GetVmsRequestBody{
+		Where: &models.VMWhereInput{
+			ID: &id,
+		},
+	}
+	vms, err := api.VM.GetVms(gvp)
+	if err != nil {
+		diags.AddError("Failed to get vm", fmt.Sprintf("Unable to get vm %s, got error: %s", id, err))
+		return nil, diags
+	}
+	if len(vms.Payload) == 0 {
+		return nil, diags
+	}
+	return vms.Payload[0], diags
+}
+
+// GetVmByIdOrName returns nil vm without error when vm not found, id takes precedence over
+// a vm named like another vm's id, several vms of the name is an error.
+func GetVmByIdOrName(api *apiclient.Cloudtower, ref string) (*models.VM, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	gvp := vm.NewGetVmsParams()
+	gvp.RequestBody = &models.GetVmsRequestBody{
+		Where: &models.VMWhereInput{
+			OR: []*models.VMWhereInput{
+				{ID: &ref},
+				{Name: &ref},
+			},
+		},
+	}
+	vms, err := api.VM.GetVms(gvp)
+	if err != nil {
+		diags.AddError("Failed to get vm", fmt.Sprintf("Unable to get vm %s, got error: %s", ref, err))
+		return nil, diags
+	}
+	for _, v := range vms.Payload {
+		if v.ID != nil && *v.ID == ref {
+			return v, diags
+		}
+	}
+	switch len(vms.Payload) {
+	case 0:
+		return nil, diags
+	case 1:
+		return vms.Payload[0], diags
+	default:
+		diags.AddError("Failed to get vm", fmt.Sprintf("Several vms are named %s, reference the vm by id instead", ref))
+		return nil, diags
 	}
-	output.Ips, _ = types.ListValueFrom(context.Background(), types.StringType, aips)
 }
@@ -7,6 +7,11 @@ import (
 	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/everoute_package"
 	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/everoute_service"
 	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/global_security_policy"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/network_service"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/policy_compliance"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/policy_document"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/policy_evaluation"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/datasources/rule_presets"
 )
 
 func (p *EverouteProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
@@ -14,5 +19,10 @@ func (p *EverouteProvider) DataSources(ctx context.Context) []func() datasource.
 		func() datasource.DataSource { return &everoute_package.DataSource{} },
 		func() datasource.DataSource { return &everoute_service.DataSource{} },
 		func() datasource.DataSource { return &global_security_policy.DataSource{} },
+		func() datasource.DataSource { return &network_service.DataSource{} },
+		func() datasource.DataSource { return &policy_compliance.DataSource{} },
+		func() datasource.DataSource { return &policy_document.DataSource{} },
+		func() datasource.DataSource { return &policy_evaluation.DataSource{} },
+		func() datasource.DataSource { return &rule_presets.DataSource{} },
 	}
 }
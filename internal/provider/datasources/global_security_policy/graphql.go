@@ -26,6 +26,9 @@ query everouteClusters(
 		  ports {
 			port
 			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
 		  }
 		  selector {
 			id
@@ -41,6 +44,9 @@ query everouteClusters(
 		  ports {
 			port
 			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
 		  }
 		  selector {
 			id
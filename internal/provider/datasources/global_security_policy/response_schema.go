@@ -152,7 +152,7 @@ func flattenNetworkPolicyRule(ctx context.Context, input *gjson.Result) []Networ
 		if gjt.Exists() {
 			state.Type = types.StringValue(gjt.String())
 		}
-		gjss := rule.Get("selectors")
+		gjss := rule.Get("selector")
 		if gjss.Exists() {
 			for _, gjs := range gjss.Array() {
 				selector := label_helper.LabelModel{}
@@ -166,15 +166,18 @@ func flattenNetworkPolicyRule(ctx context.Context, input *gjson.Result) []Networ
 }
 
 type NetworkPolicyRulePortModel struct {
-	Protocol types.String   `tfsdk:"protocol"`
-	Port     []types.String `tfsdk:"port"`
+	Protocol    types.String   `tfsdk:"protocol"`
+	Port        []types.String `tfsdk:"port"`
+	AlgProtocol types.String   `tfsdk:"alg_protocol"`
+	ICMPType    types.Int64    `tfsdk:"icmp_type"`
+	ICMPCode    types.Int64    `tfsdk:"icmp_code"`
 }
 
 func networkPolicyRulePortSchema() schema.NestedAttributeObject {
 	return schema.NestedAttributeObject{
 		Attributes: map[string]schema.Attribute{
 			"protocol": schema.StringAttribute{
-				MarkdownDescription: "network policy rule's protocol, valid value: TCP, UDP, ICMP",
+				MarkdownDescription: "network policy rule's protocol, valid value: TCP, UDP, ICMP, IPIP, ALG",
 				Computed:            true,
 				Optional:            false,
 				Required:            false,
@@ -186,6 +189,24 @@ func networkPolicyRulePortSchema() schema.NestedAttributeObject {
 				Required:            false,
 				ElementType:         types.StringType,
 			},
+			"alg_protocol": schema.StringAttribute{
+				MarkdownDescription: "network policy rule's application layer protocol of ALG protocol, valid value: FTP, TFTP",
+				Computed:            true,
+				Optional:            false,
+				Required:            false,
+			},
+			"icmp_type": schema.Int64Attribute{
+				MarkdownDescription: "network policy rule's icmp type of ICMP protocol, null for all icmp types",
+				Computed:            true,
+				Optional:            false,
+				Required:            false,
+			},
+			"icmp_code": schema.Int64Attribute{
+				MarkdownDescription: "network policy rule's icmp code of ICMP protocol, null for all codes of the icmp type",
+				Computed:            true,
+				Optional:            false,
+				Required:            false,
+			},
 		},
 	}
 }
@@ -198,7 +219,7 @@ func flattenNetworkPolicyRulePorts(ctx context.Context, input *gjson.Result) []N
 	ports := input.Array()
 	result := make([]NetworkPolicyRulePortModel, 0, len(ports))
 	for _, p := range ports {
-		port := NetworkPolicyRulePortModel{}
+		port := NetworkPolicyRulePortModel{ICMPType: types.Int64Null(), ICMPCode: types.Int64Null()}
 		gjps := p.Get("port")
 		if gjps.Exists() && gjps.Type == gjson.String {
 			ports := strings.Split(gjps.String(), ",")
@@ -210,6 +231,16 @@ func flattenNetworkPolicyRulePorts(ctx context.Context, input *gjson.Result) []N
 		if gjpt.Type == gjson.String {
 			port.Protocol = types.StringValue(gjpt.String())
 		}
+		gjpa := p.Get("alg_protocol")
+		if gjpa.Type == gjson.String {
+			port.AlgProtocol = types.StringValue(gjpa.String())
+		}
+		if gjit := p.Get("icmp_type"); gjit.Type == gjson.Number {
+			port.ICMPType = types.Int64Value(gjit.Int())
+		}
+		if gjic := p.Get("icmp_code"); gjic.Type == gjson.Number {
+			port.ICMPCode = types.Int64Value(gjic.Int())
+		}
 		result = append(result, port)
 	}
 	return result
@@ -0,0 +1,191 @@
+package network_service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tidwall/gjson"
+
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DataSource{}
+var _ datasource.DataSourceWithConfigValidators = &DataSource{}
+
+func NewDataSource() datasource.DataSource {
+	return &DataSource{}
+}
+
+// DataSource defines the data source implementation.
+type DataSource struct {
+	client *everoute.Client
+}
+
+// NetworkServiceDataSourceModel describes the data source data model.
+type NetworkServiceDataSourceModel struct {
+	Id          types.String `tfsdk:"id"`
+	ServiceId   types.String `tfsdk:"service_id"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Entries     []EntryModel `tfsdk:"entries"`
+}
+
+type EntryModel struct {
+	Protocol types.String         `tfsdk:"protocol"`
+	Port     port_helper.PortSpec `tfsdk:"port"`
+	ICMPType types.Int64          `tfsdk:"icmp_type"`
+}
+
+func (d *DataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_network_service"
+}
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "Get everoute network service by id or name",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "query network service by id, must provided if name not provided",
+				Optional:            true,
+				Computed:            true,
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "query network service by name, must provided if id not provided",
+				Optional:            true,
+				Computed:            true,
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id network service belongs to, used to narrow query by name",
+				Optional:            true,
+				Computed:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "network service's description",
+				Computed:            true,
+			},
+			"entries": schema.ListNestedAttribute{
+				MarkdownDescription: "protocol and port entries of the network service",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"protocol": schema.StringAttribute{
+							MarkdownDescription: "entry's protocol, valid value: TCP, UDP, ICMP",
+							Computed:            true,
+						},
+						"port": schema.StringAttribute{
+							MarkdownDescription: "entry's ports and port ranges, seperate by comma, null for all ports",
+							CustomType:          port_helper.PortSpecType{},
+							Computed:            true,
+						},
+						"icmp_type": schema.Int64Attribute{
+							MarkdownDescription: "entry's icmp type, null for all icmp types",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d DataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.AtLeastOneOf(
+			path.MatchRoot("id"),
+			path.MatchRoot("name"),
+		),
+	}
+}
+
+func (d *DataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state NetworkServiceDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	whereInput := make(map[string]interface{})
+	if !state.Id.IsNull() {
+		whereInput["id"] = state.Id.ValueString()
+	}
+	if !state.Name.IsNull() {
+		whereInput["name"] = state.Name.ValueString()
+	}
+	if !state.ServiceId.IsNull() {
+		whereInput["everoute_cluster"] = map[string]interface{}{
+			"id": state.ServiceId.ValueString(),
+		}
+	}
+
+	gqlResp, _, err := d.client.DgqlApi.Raw(ctx, getNetworkServicesDocument, "networkPolicyRuleServices", map[string]interface{}{
+		"where": whereInput,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("read network service failed", err.Error())
+		return
+	}
+	jServices := gqlResp.Get("networkPolicyRuleServices").Array()
+	if len(jServices) == 0 {
+		resp.Diagnostics.AddError("read network service failed", "no network service matches the query")
+		return
+	}
+	if len(jServices) > 1 {
+		resp.Diagnostics.AddError(
+			"read network service failed",
+			fmt.Sprintf("%d network services match the query, specify id or service_id to narrow it", len(jServices)),
+		)
+		return
+	}
+	jService := jServices[0]
+	state.Id = types.StringValue(jService.Get("id").String())
+	state.Name = types.StringValue(jService.Get("name").String())
+	state.ServiceId = types.StringValue(jService.Get("everoute_cluster.id").String())
+	state.Description = types.StringValue(jService.Get("description").String())
+	state.Entries = make([]EntryModel, 0)
+	for _, je := range jService.Get("members").Array() {
+		e := EntryModel{
+			Protocol: types.StringValue(je.Get("protocol").String()),
+			Port:     port_helper.NewPortSpecNull(),
+			ICMPType: types.Int64Null(),
+		}
+		if jp := je.Get("port"); jp.Type != gjson.Null && jp.String() != "" {
+			e.Port = port_helper.NewPortSpecValue(jp.String())
+		}
+		if jt := je.Get("icmp_type"); jt.Type != gjson.Null {
+			e.ICMPType = types.Int64Value(jt.Int())
+		}
+		state.Entries = append(state.Entries, e)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
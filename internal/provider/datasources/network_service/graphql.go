@@ -0,0 +1,19 @@
+package network_service
+
+var getNetworkServicesDocument = `
+query networkPolicyRuleServices($where: NetworkPolicyRuleServiceWhereInput) {
+	networkPolicyRuleServices(where: $where) {
+	  id
+	  name
+	  description
+	  everoute_cluster {
+		id
+	  }
+	  members {
+		protocol
+		port
+		icmp_type
+	  }
+	}
+  }
+`
@@ -0,0 +1,435 @@
+package policy_compliance
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/datasourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	directionIngress = "INGRESS"
+	directionEgress  = "EGRESS"
+	directionBoth    = "BOTH"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DataSource{}
+var _ datasource.DataSourceWithConfigValidators = &DataSource{}
+
+func NewDataSource() datasource.DataSource {
+	return &DataSource{}
+}
+
+// DataSource defines the data source implementation.
+type DataSource struct {
+	client *everoute.Client
+}
+
+// PolicyComplianceDataSourceModel describes the data source data model.
+type PolicyComplianceDataSourceModel struct {
+	Id             types.String      `tfsdk:"id"`
+	ServiceName    types.String      `tfsdk:"service_name"`
+	ServiceId      types.String      `tfsdk:"service_id"`
+	Constraints    []ConstraintModel `tfsdk:"constraints"`
+	Compliant      types.Bool        `tfsdk:"compliant"`
+	Violations     []ViolationModel  `tfsdk:"violations"`
+	UncheckedRules []ViolationModel  `tfsdk:"unchecked_rules"`
+}
+
+// ConstraintModel sets exactly one of default_action, whitelist_enabled and forbid.
+type ConstraintModel struct {
+	Name             types.String        `tfsdk:"name"`
+	DefaultAction    types.String        `tfsdk:"default_action"`
+	WhitelistEnabled types.Bool          `tfsdk:"whitelist_enabled"`
+	Forbid           *ForbiddenRuleModel `tfsdk:"forbid"`
+}
+
+// ForbiddenRuleModel describes traffic no rule may allow.
+type ForbiddenRuleModel struct {
+	Direction        types.String `tfsdk:"direction"`
+	CIDR             types.String `tfsdk:"cidr"`
+	Protocol         types.String `tfsdk:"protocol"`
+	Ports            types.String `tfsdk:"ports"`
+	MatchOverlapping types.Bool   `tfsdk:"match_overlapping"`
+}
+
+type ViolationModel struct {
+	Constraint types.String `tfsdk:"constraint"`
+	Direction  types.String `tfsdk:"direction"`
+	RuleIndex  types.Int64  `tfsdk:"rule_index"`
+	Message    types.String `tfsdk:"message"`
+}
+
+func (d *DataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policy_compliance"
+}
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "check everoute service's global security policy against constraints, " +
+			"forbidden traffic is checked against all global whitelist rules, also when the whitelist is disabled, " +
+			"selector and security group rules allowing the forbidden traffic cannot be checked as their peers are not known by ip, " +
+			"they are reported in unchecked_rules and the policy is not compliant",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "check's identifier",
+				Computed:            true,
+			},
+			"service_name": schema.StringAttribute{
+				MarkdownDescription: "check global security policy by service name, must provided if service_id not provided",
+				Optional:            true,
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "check global security policy by service id, must provided if service_name not provided",
+				Optional:            true,
+			},
+			"constraints": schema.ListNestedAttribute{
+				MarkdownDescription: "constraints the global security policy must satisfy",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "constraint's name, reported in violations",
+							Required:            true,
+						},
+						"default_action": schema.StringAttribute{
+							MarkdownDescription: "required global default action, valid value: ALLOW, DROP",
+							Optional:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("ALLOW", "DROP"),
+								stringvalidator.ExactlyOneOf(
+									path.MatchRelative().AtParent().AtName("whitelist_enabled"),
+									path.MatchRelative().AtParent().AtName("forbid"),
+								),
+							},
+						},
+						"whitelist_enabled": schema.BoolAttribute{
+							MarkdownDescription: "required state of the global whitelist",
+							Optional:            true,
+						},
+						"forbid": schema.SingleNestedAttribute{
+							MarkdownDescription: "traffic no global whitelist rule may allow",
+							Optional:            true,
+							Attributes: map[string]schema.Attribute{
+								"direction": schema.StringAttribute{
+									MarkdownDescription: "direction of rules checked, valid value: INGRESS, EGRESS, BOTH",
+									Required:            true,
+									Validators: []validator.String{
+										stringvalidator.OneOf(directionIngress, directionEgress, directionBoth),
+									},
+								},
+								"cidr": schema.StringAttribute{
+									MarkdownDescription: "peers no rule may allow, like 0.0.0.0/0, rules whose ip block contains the cidr violate",
+									Required:            true,
+									Validators: []validator.String{
+										ip_helper.GetIPBlockValidator(),
+									},
+								},
+								"protocol": schema.StringAttribute{
+									MarkdownDescription: "protocol no rule may allow, valid value: TCP, UDP, ICMP, IPIP",
+									Required:            true,
+									Validators: []validator.String{
+										stringvalidator.OneOf(
+											network_policy_helper.ProtocolTCP,
+											network_policy_helper.ProtocolUDP,
+											network_policy_helper.ProtocolICMP,
+											network_policy_helper.ProtocolIPIP,
+										),
+									},
+								},
+								"ports": schema.StringAttribute{
+									MarkdownDescription: "ports and port ranges like 22,3389 no rule may allow, only for TCP and UDP, unset for any port",
+									Optional:            true,
+									Validators: []validator.String{
+										port_helper.GetPortSpecValidator(),
+									},
+								},
+								"match_overlapping": schema.BoolAttribute{
+									MarkdownDescription: "rules whose ip block overlaps the cidr also violate",
+									Optional:            true,
+								},
+							},
+						},
+					},
+				},
+			},
+			"compliant": schema.BoolAttribute{
+				MarkdownDescription: "if no constraint is violated and no rule is left unchecked",
+				Computed:            true,
+			},
+			"violations": schema.ListNestedAttribute{
+				MarkdownDescription: "constraints violated",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"constraint": schema.StringAttribute{
+							MarkdownDescription: "name of the constraint violated",
+							Computed:            true,
+						},
+						"direction": schema.StringAttribute{
+							MarkdownDescription: "direction of the violating rule, INGRESS or EGRESS",
+							Computed:            true,
+						},
+						"rule_index": schema.Int64Attribute{
+							MarkdownDescription: "index of the violating rule among rules of its direction",
+							Computed:            true,
+						},
+						"message": schema.StringAttribute{
+							MarkdownDescription: "violation's description",
+							Computed:            true,
+						},
+					},
+				},
+			},
+			"unchecked_rules": schema.ListNestedAttribute{
+				MarkdownDescription: "selector and security group rules which may allow forbidden traffic but cannot be checked",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"constraint": schema.StringAttribute{
+							MarkdownDescription: "name of the constraint not checked",
+							Computed:            true,
+						},
+						"direction": schema.StringAttribute{
+							MarkdownDescription: "direction of the unchecked rule, INGRESS or EGRESS",
+							Computed:            true,
+						},
+						"rule_index": schema.Int64Attribute{
+							MarkdownDescription: "index of the unchecked rule among rules of its direction",
+							Computed:            true,
+						},
+						"message": schema.StringAttribute{
+							MarkdownDescription: "description of the unchecked rule",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d DataSource) ConfigValidators(ctx context.Context) []datasource.ConfigValidator {
+	return []datasource.ConfigValidator{
+		datasourcevalidator.AtLeastOneOf(
+			path.MatchRoot("service_name"),
+			path.MatchRoot("service_id"),
+		),
+	}
+}
+
+func (d *DataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state PolicyComplianceDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var whereInput = make(map[string]interface{})
+	if !state.ServiceId.IsNull() {
+		whereInput["id"] = state.ServiceId.ValueString()
+	} else {
+		whereInput["name"] = state.ServiceName.ValueString()
+	}
+
+	gqlResp, _, err := d.client.DgqlApi.Raw(ctx, getWhiteListDocument, "everouteClusters", map[string]interface{}{
+		"where": whereInput,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("read service global whitelist failed", err.Error())
+		return
+	}
+	jServices := gqlResp.Get("everouteClusters").Array()
+	if len(jServices) == 0 {
+		resp.Diagnostics.AddError("Everoute service not found", fmt.Sprintf("everoute service %v not found", whereInput))
+		return
+	}
+	// a check of an arbitrary one of same-named services may pass for the wrong service
+	if len(jServices) > 1 {
+		resp.Diagnostics.AddError("Several everoute services found",
+			fmt.Sprintf("several everoute services are named %s, check by service_id instead", state.ServiceName.ValueString()))
+		return
+	}
+	jService := jServices[0]
+	services, diags := d.getServiceMembers(ctx, &jService)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Violations = make([]ViolationModel, 0)
+	state.UncheckedRules = make([]ViolationModel, 0)
+	for idx := range state.Constraints {
+		violations, unchecked, diags := checkConstraint(ctx, &state.Constraints[idx], &jService, services)
+		resp.Diagnostics.Append(diags...)
+		state.Violations = append(state.Violations, violations...)
+		state.UncheckedRules = append(state.UncheckedRules, unchecked...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// a rule not checked may violate, so the policy is not compliant by omission
+	state.Compliant = types.BoolValue(len(state.Violations) == 0 && len(state.UncheckedRules) == 0)
+
+	if state.ServiceName.IsNull() {
+		state.ServiceName = types.StringValue(jService.Get("name").String())
+	}
+	if state.ServiceId.IsNull() {
+		state.ServiceId = types.StringValue(jService.Get("id").String())
+	}
+	state.Id = types.StringValue(strconv.FormatInt(time.Now().Unix(), 10))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// checkConstraint returns rules violating the constraint, and rules which may violate but cannot be checked.
+func checkConstraint(ctx context.Context, constraint *ConstraintModel, jService *gjson.Result, services map[string][]gjson.Result) ([]ViolationModel, []ViolationModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	violations := make([]ViolationModel, 0)
+	unchecked := make([]ViolationModel, 0)
+	violation := func(direction string, index int64, message string) ViolationModel {
+		v := ViolationModel{
+			Constraint: constraint.Name,
+			Direction:  types.StringNull(),
+			RuleIndex:  types.Int64Null(),
+			Message:    types.StringValue(message),
+		}
+		if direction != "" {
+			v.Direction = types.StringValue(direction)
+			v.RuleIndex = types.Int64Value(index)
+		}
+		return v
+	}
+
+	switch {
+	case !constraint.DefaultAction.IsNull():
+		if action := jService.Get("global_default_action").String(); action != constraint.DefaultAction.ValueString() {
+			violations = append(violations, violation("", 0,
+				fmt.Sprintf("global default action is %s, expected %s", action, constraint.DefaultAction.ValueString())))
+		}
+	case !constraint.WhitelistEnabled.IsNull():
+		if enabled := jService.Get("global_whitelist.enable").Bool(); enabled != constraint.WhitelistEnabled.ValueBool() {
+			violations = append(violations, violation("", 0,
+				fmt.Sprintf("global whitelist enable is %t, expected %t", enabled, constraint.WhitelistEnabled.ValueBool())))
+		}
+	case constraint.Forbid != nil:
+		forbid := constraint.Forbid
+		block, err := ip_helper.ParseIPBlock(forbid.CIDR.ValueString())
+		if err != nil {
+			diags.AddError("Invalid constraint", err.Error())
+			return violations, unchecked, diags
+		}
+		protocol := forbid.Protocol.ValueString()
+		ranges, err := port_helper.ParsePortSpec(forbid.Ports.ValueString())
+		if err != nil {
+			diags.AddError("Invalid constraint", err.Error())
+			return violations, unchecked, diags
+		}
+		if len(ranges) > 0 && protocol != network_policy_helper.ProtocolTCP && protocol != network_policy_helper.ProtocolUDP {
+			diags.AddError("Invalid constraint", fmt.Sprintf("ports of constraint %s are not allowed when protocol is %s", constraint.Name.ValueString(), protocol))
+			return violations, unchecked, diags
+		}
+		directions := []string{directionIngress, directionEgress}
+		if d := forbid.Direction.ValueString(); d != directionBoth {
+			directions = []string{d}
+		}
+		for _, direction := range directions {
+			jrules := jService.Get("global_whitelist." + strings.ToLower(direction))
+			rules := network_policy_helper.ReadGqlResultToPeerRules(&jrules)
+			for idx, jrule := range jrules.Array() {
+				jrule := jrule
+				matched, evaluable := network_policy_helper.GqlResultRulePeerCovers(&jrule, block, forbid.MatchOverlapping.ValueBool())
+				if evaluable && !matched {
+					continue
+				}
+				if !network_policy_helper.GqlResultRuleAllowsPorts(&jrule, services, protocol, ranges) {
+					continue
+				}
+				if !evaluable {
+					unchecked = append(unchecked, violation(direction, int64(idx), fmt.Sprintf("%s rule (%s) may allow forbidden %s traffic of %s, its peers are not known by ip",
+						strings.ToLower(direction), network_policy_helper.DescribePeerRule(ctx, &rules[idx]), forbidden(protocol, forbid.Ports.ValueString()), block)))
+					continue
+				}
+				violations = append(violations, violation(direction, int64(idx), fmt.Sprintf("%s rule (%s) allows forbidden %s traffic of %s",
+					strings.ToLower(direction), network_policy_helper.DescribePeerRule(ctx, &rules[idx]), forbidden(protocol, forbid.Ports.ValueString()), block)))
+			}
+		}
+	}
+	return violations, unchecked, diags
+}
+
+func forbidden(protocol string, ports string) string {
+	if ports == "" {
+		return protocol
+	}
+	return protocol + " " + ports
+}
+
+// getServiceMembers returns members of network services referenced by global whitelist rules, by service id.
+func (d *DataSource) getServiceMembers(ctx context.Context, jService *gjson.Result) (map[string][]gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	members := make(map[string][]gjson.Result)
+	ids := make([]string, 0)
+	for _, p := range []string{"global_whitelist.ingress.#.services", "global_whitelist.egress.#.services"} {
+		for _, jservices := range jService.Get(p).Array() {
+			for _, js := range jservices.Array() {
+				ids = append(ids, js.String())
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return members, diags
+	}
+	gqlResp, _, err := d.client.DgqlApi.Raw(ctx, getNetworkServicesDocument, "networkPolicyRuleServices", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id_in": ids,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError("read network services failed", err.Error())
+		return members, diags
+	}
+	for _, js := range gqlResp.Get("networkPolicyRuleServices").Array() {
+		members[js.Get("id").String()] = js.Get("members").Array()
+	}
+	return members, diags
+}
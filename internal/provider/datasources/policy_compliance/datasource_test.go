@@ -0,0 +1,47 @@
+package policy_compliance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/tidwall/gjson"
+)
+
+func TestCheckConstraintReportsUncheckedRules(t *testing.T) {
+	jService := gjson.Parse(`{
+		"global_default_action": "DROP",
+		"global_whitelist": {
+			"enable": true,
+			"ingress": [
+				{"type": "IP_BLOCK", "ip_block": "0.0.0.0/0", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}]},
+				{"type": "SELECTOR", "selector": [{"id": "label-1", "key": "role", "value": "ops"}], "except_ip_block": [], "services": [], "ports": []},
+				{"type": "SECURITY_GROUP", "security_group_id": "group-1", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "80"}]}
+			],
+			"egress": []
+		}
+	}`)
+	constraint := &ConstraintModel{
+		Name:             types.StringValue("no-public-ssh"),
+		DefaultAction:    types.StringNull(),
+		WhitelistEnabled: types.BoolNull(),
+		Forbid: &ForbiddenRuleModel{
+			Direction:        types.StringValue(directionIngress),
+			CIDR:             types.StringValue("0.0.0.0/0"),
+			Protocol:         types.StringValue("TCP"),
+			Ports:            types.StringValue("22"),
+			MatchOverlapping: types.BoolNull(),
+		},
+	}
+	violations, unchecked, diags := checkConstraint(context.Background(), constraint, &jService, nil)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(violations) != 1 || violations[0].RuleIndex.ValueInt64() != 0 {
+		t.Errorf("violations = %v, want the ip block rule", violations)
+	}
+	// the security group rule allows port 80 only, so it cannot violate
+	if len(unchecked) != 1 || unchecked[0].RuleIndex.ValueInt64() != 1 {
+		t.Errorf("unchecked rules = %v, want the selector rule allowing all traffic", unchecked)
+	}
+}
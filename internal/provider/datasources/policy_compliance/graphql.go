@@ -0,0 +1,53 @@
+package policy_compliance
+
+var getWhiteListDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where) {
+	  id
+	  name
+	  global_default_action
+	  global_whitelist {
+		enable
+		ingress {
+		  type
+		  ip_block
+		  except_ip_block
+		  services
+		  ports {
+			port
+			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
+		  }
+		}
+		egress {
+		  type
+		  ip_block
+		  except_ip_block
+		  services
+		  ports {
+			port
+			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
+		  }
+		}
+	  }
+	}
+  }
+`
+
+var getNetworkServicesDocument = `
+query networkPolicyRuleServices($where: NetworkPolicyRuleServiceWhereInput) {
+	networkPolicyRuleServices(where: $where) {
+	  id
+	  members {
+		protocol
+		port
+		icmp_type
+	  }
+	}
+  }
+`
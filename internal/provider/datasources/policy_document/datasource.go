@@ -0,0 +1,475 @@
+package policy_document
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+)
+
+const (
+	directionIngress = "INGRESS"
+	directionEgress  = "EGRESS"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DataSource{}
+
+func NewDataSource() datasource.DataSource {
+	return &DataSource{}
+}
+
+// DataSource defines the data source implementation, documents are built locally without cloudtower.
+type DataSource struct{}
+
+// PolicyDocumentDataSourceModel describes the data source data model.
+type PolicyDocumentDataSourceModel struct {
+	Id         types.String                 `tfsdk:"id"`
+	Statements []PolicyStatementModel       `tfsdk:"statements"`
+	Ingress    []PolicyDocumentRuleModel    `tfsdk:"ingress"`
+	Egress     []PolicyDocumentRuleModel    `tfsdk:"egress"`
+	Dropped    []PolicyDocumentDroppedModel `tfsdk:"dropped_rules"`
+}
+
+// PolicyStatementModel allows protocols and network services from or to a list of cidrs.
+type PolicyStatementModel struct {
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Directions  types.List   `tfsdk:"directions"`
+	CIDRs       types.List   `tfsdk:"cidrs"`
+	ExceptCIDRs types.List   `tfsdk:"except_cidrs"`
+	Protocols   types.List   `tfsdk:"protocols"`
+	TCPPorts    types.String `tfsdk:"tcp_ports"`
+	UDPPorts    types.String `tfsdk:"udp_ports"`
+	ServiceIds  types.List   `tfsdk:"network_service_ids"`
+}
+
+// PolicyDocumentRuleModel is a rule assignable to ingress or egress of everoute_global_security_policy.
+type PolicyDocumentRuleModel struct {
+	Name          types.String `tfsdk:"name"`
+	Description   types.String `tfsdk:"description"`
+	Type          types.String `tfsdk:"type"`
+	IPBlock       types.String `tfsdk:"ip_block"`
+	ExceptIPBlock types.List   `tfsdk:"except_ip_block"`
+	TCPEnabled    types.Bool   `tfsdk:"tcp_enabled"`
+	TCPPorts      types.String `tfsdk:"tcp_ports"`
+	UDPEnabled    types.Bool   `tfsdk:"udp_enabled"`
+	UDPPorts      types.String `tfsdk:"udp_ports"`
+	ICMPEnabled   types.Bool   `tfsdk:"icmp_enabled"`
+	ServiceIds    types.List   `tfsdk:"network_service_ids"`
+}
+
+// PolicyDocumentDroppedModel records a rule left out as another rule allows all its traffic.
+type PolicyDocumentDroppedModel struct {
+	Direction types.String `tfsdk:"direction"`
+	Rule      types.String `tfsdk:"rule"`
+	Reason    types.String `tfsdk:"reason"`
+}
+
+func (d *DataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policy_document"
+}
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "compose global security policy rules from statements, " +
+			"a statement allows protocols and network services from (ingress) or to (egress) each of its cidrs. " +
+			"Rules duplicating or covered by another rule of the same direction are dropped, " +
+			"ingress and egress are assignable to ingress and egress of everoute_global_security_policy",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "document's identifier, a hash of its rules",
+				Computed:            true,
+			},
+			"statements": schema.ListNestedAttribute{
+				MarkdownDescription: "statements composing the document",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "statement's name, names rules of the statement, followed by the cidr if the statement has several cidrs",
+							Optional:            true,
+						},
+						"description": schema.StringAttribute{
+							MarkdownDescription: "statement's description, describes rules of the statement",
+							Optional:            true,
+						},
+						"directions": schema.ListAttribute{
+							MarkdownDescription: "directions of the statement's rules, valid value: INGRESS, EGRESS",
+							ElementType:         types.StringType,
+							Required:            true,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.UniqueValues(),
+								listvalidator.ValueStringsAre(stringvalidator.OneOf(directionIngress, directionEgress)),
+							},
+						},
+						"cidrs": schema.ListAttribute{
+							MarkdownDescription: "sources of ingress and destinations of egress, cidrs or ips",
+							ElementType:         types.StringType,
+							Required:            true,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.ValueStringsAre(ip_helper.GetIPBlockValidator()),
+							},
+						},
+						"except_cidrs": schema.ListAttribute{
+							MarkdownDescription: "cidrs excluded, each must be contained in one of cidrs",
+							ElementType:         types.StringType,
+							Optional:            true,
+							Validators: []validator.List{
+								listvalidator.ValueStringsAre(ip_helper.GetIPBlockValidator()),
+							},
+						},
+						"protocols": schema.ListAttribute{
+							MarkdownDescription: "protocols allowed, valid value: TCP, UDP, ICMP",
+							ElementType:         types.StringType,
+							Optional:            true,
+							Validators: []validator.List{
+								listvalidator.UniqueValues(),
+								listvalidator.ValueStringsAre(stringvalidator.OneOf(
+									network_policy_helper.ProtocolTCP,
+									network_policy_helper.ProtocolUDP,
+									network_policy_helper.ProtocolICMP,
+								)),
+							},
+						},
+						"tcp_ports": schema.StringAttribute{
+							MarkdownDescription: "tcp ports and port ranges like 80,8000-8080 when TCP is allowed, unset for all ports",
+							Optional:            true,
+							Validators: []validator.String{
+								port_helper.GetPortSpecValidator(),
+							},
+						},
+						"udp_ports": schema.StringAttribute{
+							MarkdownDescription: "udp ports and port ranges like 53 when UDP is allowed, unset for all ports",
+							Optional:            true,
+							Validators: []validator.String{
+								port_helper.GetPortSpecValidator(),
+							},
+						},
+						"network_service_ids": schema.ListAttribute{
+							MarkdownDescription: "ids of network services allowed, look them up by name with everoute_network_service",
+							ElementType:         types.StringType,
+							Optional:            true,
+						},
+					},
+				},
+			},
+			"ingress": schema.ListNestedAttribute{
+				MarkdownDescription: "ingress rules composed",
+				Computed:            true,
+				NestedObject:        documentRuleSchema(),
+			},
+			"egress": schema.ListNestedAttribute{
+				MarkdownDescription: "egress rules composed",
+				Computed:            true,
+				NestedObject:        documentRuleSchema(),
+			},
+			"dropped_rules": schema.ListNestedAttribute{
+				MarkdownDescription: "rules left out as another rule of the same direction allows all their traffic",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"direction": schema.StringAttribute{
+							MarkdownDescription: "direction of the rule, INGRESS or EGRESS",
+							Computed:            true,
+						},
+						"rule": schema.StringAttribute{
+							MarkdownDescription: "description of the rule",
+							Computed:            true,
+						},
+						"reason": schema.StringAttribute{
+							MarkdownDescription: "the rule it duplicates or is covered by",
+							Computed:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func documentRuleSchema() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				MarkdownDescription: "rule's name",
+				Computed:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "rule's description",
+				Computed:            true,
+			},
+			"type": schema.StringAttribute{
+				MarkdownDescription: "rule's peer type, always IP_BLOCK",
+				Computed:            true,
+			},
+			"ip_block": schema.StringAttribute{
+				MarkdownDescription: "rule included ip block",
+				Computed:            true,
+			},
+			"except_ip_block": schema.ListAttribute{
+				MarkdownDescription: "rule excluded ip block",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"tcp_enabled": schema.BoolAttribute{
+				MarkdownDescription: "if tcp is allowed",
+				Computed:            true,
+			},
+			"tcp_ports": schema.StringAttribute{
+				MarkdownDescription: "tcp ports allowed, empty for all ports",
+				Computed:            true,
+			},
+			"udp_enabled": schema.BoolAttribute{
+				MarkdownDescription: "if udp is allowed",
+				Computed:            true,
+			},
+			"udp_ports": schema.StringAttribute{
+				MarkdownDescription: "udp ports allowed, empty for all ports",
+				Computed:            true,
+			},
+			"icmp_enabled": schema.BoolAttribute{
+				MarkdownDescription: "if icmp is allowed",
+				Computed:            true,
+			},
+			"network_service_ids": schema.ListAttribute{
+				MarkdownDescription: "ids of network services allowed",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state PolicyDocumentDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	rules := map[string][]PolicyDocumentRuleModel{
+		directionIngress: make([]PolicyDocumentRuleModel, 0),
+		directionEgress:  make([]PolicyDocumentRuleModel, 0),
+	}
+	for idx := range state.Statements {
+		statementPath := path.Root("statements").AtListIndex(idx)
+		statementRules, directions, diags := buildStatementRules(ctx, &state.Statements[idx], statementPath)
+		resp.Diagnostics.Append(diags...)
+		for _, direction := range directions {
+			rules[direction] = append(rules[direction], statementRules...)
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Dropped = make([]PolicyDocumentDroppedModel, 0)
+	for _, direction := range []string{directionIngress, directionEgress} {
+		var dropped []PolicyDocumentDroppedModel
+		rules[direction], dropped = dropCoveredRules(ctx, direction, rules[direction])
+		state.Dropped = append(state.Dropped, dropped...)
+	}
+	state.Ingress = rules[directionIngress]
+	state.Egress = rules[directionEgress]
+
+	id, diags := documentId(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	state.Id = types.StringValue(id)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// buildStatementRules returns a rule of each cidr of the statement, with the statement's directions.
+func buildStatementRules(ctx context.Context, statement *PolicyStatementModel, statementPath path.Path) ([]PolicyDocumentRuleModel, []string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var directions, cidrs, excepts, protocols, services []string
+	diags.Append(statement.Directions.ElementsAs(ctx, &directions, false)...)
+	diags.Append(statement.CIDRs.ElementsAs(ctx, &cidrs, false)...)
+	if !statement.ExceptCIDRs.IsNull() {
+		diags.Append(statement.ExceptCIDRs.ElementsAs(ctx, &excepts, false)...)
+	}
+	if !statement.Protocols.IsNull() {
+		diags.Append(statement.Protocols.ElementsAs(ctx, &protocols, false)...)
+	}
+	services = make([]string, 0)
+	if !statement.ServiceIds.IsNull() {
+		diags.Append(statement.ServiceIds.ElementsAs(ctx, &services, false)...)
+	}
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+	if len(protocols) == 0 && len(services) == 0 {
+		diags.AddAttributeError(statementPath, "Invalid statement", "at least one of protocols or network_service_ids should be configured")
+	}
+
+	enabled := make(map[string]bool)
+	for _, p := range protocols {
+		enabled[p] = true
+	}
+	tcpPorts, udpPorts := "", ""
+	for protocol, spec := range map[string]types.String{network_policy_helper.ProtocolTCP: statement.TCPPorts, network_policy_helper.ProtocolUDP: statement.UDPPorts} {
+		if spec.IsNull() {
+			continue
+		}
+		attr := "tcp_ports"
+		if protocol == network_policy_helper.ProtocolUDP {
+			attr = "udp_ports"
+		}
+		if !enabled[protocol] {
+			diags.AddAttributeError(statementPath.AtName(attr), "Invalid statement",
+				fmt.Sprintf("%s is not allowed when %s is not in protocols", attr, protocol))
+			continue
+		}
+		normalized, err := port_helper.NormalizePortSpec(spec.ValueString())
+		if err != nil {
+			diags.AddAttributeError(statementPath.AtName(attr), "Invalid statement", err.Error())
+			continue
+		}
+		if protocol == network_policy_helper.ProtocolTCP {
+			tcpPorts = normalized
+		} else {
+			udpPorts = normalized
+		}
+	}
+
+	// each except belongs to the cidrs containing it
+	cidrExcepts := make([][]string, len(cidrs))
+	for _, e := range excepts {
+		contained := false
+		for idx, cidr := range cidrs {
+			if ip_helper.ValidateExceptIPBlocks(cidr, []string{e}) == nil {
+				cidrExcepts[idx] = append(cidrExcepts[idx], e)
+				contained = true
+			}
+		}
+		if !contained {
+			diags.AddAttributeError(statementPath.AtName("except_cidrs"), "Invalid statement",
+				fmt.Sprintf("except cidr %s is not contained in any of cidrs", e))
+		}
+	}
+	if diags.HasError() {
+		return nil, nil, diags
+	}
+
+	rules := make([]PolicyDocumentRuleModel, 0, len(cidrs))
+	for idx, cidr := range cidrs {
+		name := statement.Name
+		if !name.IsNull() && len(cidrs) > 1 {
+			name = types.StringValue(fmt.Sprintf("%s %s", name.ValueString(), cidr))
+		}
+		exceptValues, d := types.ListValueFrom(ctx, types.StringType, append(make([]string, 0), cidrExcepts[idx]...))
+		diags.Append(d...)
+		serviceValues, d := types.ListValueFrom(ctx, types.StringType, services)
+		diags.Append(d...)
+		rules = append(rules, PolicyDocumentRuleModel{
+			Name:          name,
+			Description:   statement.Description,
+			Type:          types.StringValue(network_policy_helper.PeerTypeIPBlock),
+			IPBlock:       types.StringValue(cidr),
+			ExceptIPBlock: exceptValues,
+			TCPEnabled:    types.BoolValue(enabled[network_policy_helper.ProtocolTCP]),
+			TCPPorts:      types.StringValue(tcpPorts),
+			UDPEnabled:    types.BoolValue(enabled[network_policy_helper.ProtocolUDP]),
+			UDPPorts:      types.StringValue(udpPorts),
+			ICMPEnabled:   types.BoolValue(enabled[network_policy_helper.ProtocolICMP]),
+			ServiceIds:    serviceValues,
+		})
+	}
+	return rules, directions, diags
+}
+
+// dropCoveredRules leaves out rules duplicating an earlier rule or covered by a broader one.
+func dropCoveredRules(ctx context.Context, direction string, rules []PolicyDocumentRuleModel) ([]PolicyDocumentRuleModel, []PolicyDocumentDroppedModel) {
+	peers := make([]network_policy_helper.PeerRuleModel, len(rules))
+	for idx, rule := range rules {
+		peers[idx] = rule.peerRule()
+	}
+	covered := make(map[int]bool)
+	dropped := make([]PolicyDocumentDroppedModel, 0)
+	for _, shadow := range network_policy_helper.AnalyzePeerRules(ctx, peers) {
+		covered[shadow.Index] = true
+		reason := fmt.Sprintf("covered by %s", shadow.CoveringRule)
+		if shadow.Duplicate {
+			reason = fmt.Sprintf("duplicates %s", shadow.CoveringRule)
+		}
+		dropped = append(dropped, PolicyDocumentDroppedModel{
+			Direction: types.StringValue(direction),
+			Rule:      types.StringValue(shadow.Rule),
+			Reason:    types.StringValue(reason),
+		})
+	}
+	kept := make([]PolicyDocumentRuleModel, 0, len(rules))
+	for idx, rule := range rules {
+		if !covered[idx] {
+			kept = append(kept, rule)
+		}
+	}
+	return kept, dropped
+}
+
+func (r *PolicyDocumentRuleModel) peerRule() network_policy_helper.PeerRuleModel {
+	excepts := make([]string, 0)
+	for _, e := range r.ExceptIPBlock.Elements() {
+		if s, ok := e.(types.String); ok {
+			excepts = append(excepts, s.ValueString())
+		}
+	}
+	return network_policy_helper.PeerRuleModel{
+		Type:            r.Type,
+		IPBlock:         ip_helper.NewIPBlockValue(r.IPBlock.ValueString()),
+		ExceptIPBlock:   ip_helper.IPBlockListValue(excepts),
+		SecurityGroupId: types.StringNull(),
+		TCPEnabled:      r.TCPEnabled,
+		TCPPorts:        port_helper.NewPortSpecValue(r.TCPPorts.ValueString()),
+		UDPEnabled:      r.UDPEnabled,
+		UDPPorts:        port_helper.NewPortSpecValue(r.UDPPorts.ValueString()),
+		ICMPEnabled:     r.ICMPEnabled,
+		ServiceIds:      r.ServiceIds,
+		Ports:           types.ListNull(types.ObjectType{AttrTypes: network_policy_helper.PortEntryAttrTypes()}),
+		Name:            r.Name,
+		Description:     r.Description,
+	}
+}
+
+// documentId hashes rules of the document, so the id changes only with them.
+func documentId(ctx context.Context, state *PolicyDocumentDataSourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	inputs := make(map[string][]map[string]interface{})
+	for direction, rules := range map[string][]PolicyDocumentRuleModel{directionIngress: state.Ingress, directionEgress: state.Egress} {
+		inputs[direction] = make([]map[string]interface{}, 0, len(rules))
+		for _, rule := range rules {
+			peer := rule.peerRule()
+			networkRule := peer.NetworkPolicyRule()
+			input, d := network_policy_helper.BuildNetworkPolicyRuleInput(ctx, &networkRule)
+			diags.Append(d...)
+			input["name"] = rule.Name.ValueString()
+			inputs[direction] = append(inputs[direction], input)
+		}
+	}
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		diags.AddError("Failed to build policy document id", err.Error())
+		return "", diags
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), diags
+}
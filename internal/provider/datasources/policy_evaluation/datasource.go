@@ -0,0 +1,388 @@
+package policy_evaluation
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DataSource{}
+var _ datasource.DataSourceWithValidateConfig = &DataSource{}
+
+func NewDataSource() datasource.DataSource {
+	return &DataSource{}
+}
+
+// DataSource defines the data source implementation.
+type DataSource struct {
+	client *everoute.Client
+}
+
+// PolicyEvaluationDataSourceModel describes the data source data model.
+type PolicyEvaluationDataSourceModel struct {
+	Id                 types.String `tfsdk:"id"`
+	ServiceId          types.String `tfsdk:"service_id"`
+	SourceIP           types.String `tfsdk:"source_ip"`
+	DestinationIP      types.String `tfsdk:"destination_ip"`
+	SourceManaged      types.Bool   `tfsdk:"source_managed"`
+	DestinationManaged types.Bool   `tfsdk:"destination_managed"`
+	Protocol           types.String `tfsdk:"protocol"`
+	Port               types.Int64  `tfsdk:"port"`
+	ICMPType           types.Int64  `tfsdk:"icmp_type"`
+	ICMPCode           types.Int64  `tfsdk:"icmp_code"`
+	Verdict            types.String `tfsdk:"verdict"`
+	Matched            types.Bool   `tfsdk:"matched"`
+	EgressMatched      types.Bool   `tfsdk:"egress_matched"`
+	EgressRuleIndex    types.Int64  `tfsdk:"egress_rule_index"`
+	EgressRule         types.String `tfsdk:"egress_rule"`
+	IngressMatched     types.Bool   `tfsdk:"ingress_matched"`
+	IngressRuleIndex   types.Int64  `tfsdk:"ingress_rule_index"`
+	IngressRule        types.String `tfsdk:"ingress_rule"`
+	SkippedRules       types.Int64  `tfsdk:"skipped_rules"`
+}
+
+// directionMatch is the first rule of a direction matching a connection.
+type directionMatch struct {
+	matched bool
+	index   int
+	rule    string
+}
+
+func (d *DataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_policy_evaluation"
+}
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "evaluate a connection against everoute service's global security policy. " +
+			"The global whitelist is enforced at every endpoint managed by the service, " +
+			"so a connection from a managed source needs an egress rule matching the destination ip, " +
+			"and a connection to a managed destination needs an ingress rule matching the source ip. " +
+			"The connection is allowed if every managed endpoint allows it by a rule of an enabled global whitelist " +
+			"or by the global default action ALLOW. " +
+			"Selector and security group rules are skipped as their peers are not known by ip, " +
+			"ALG rules match the control connection of FTP (tcp 21) and TFTP (udp 69)",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "evaluation's identifier",
+				Computed:            true,
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id",
+				Required:            true,
+			},
+			"source_ip": schema.StringAttribute{
+				MarkdownDescription: "connection's source ip",
+				Required:            true,
+			},
+			"destination_ip": schema.StringAttribute{
+				MarkdownDescription: "connection's destination ip",
+				Required:            true,
+			},
+			"source_managed": schema.BoolAttribute{
+				MarkdownDescription: "if the source is an endpoint managed by the everoute service, whose egress rules apply, defaults to true",
+				Optional:            true,
+			},
+			"destination_managed": schema.BoolAttribute{
+				MarkdownDescription: "if the destination is an endpoint managed by the everoute service, whose ingress rules apply, defaults to true",
+				Optional:            true,
+			},
+			"protocol": schema.StringAttribute{
+				MarkdownDescription: "connection's protocol, valid value: TCP, UDP, ICMP, IPIP",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(
+						network_policy_helper.ProtocolTCP,
+						network_policy_helper.ProtocolUDP,
+						network_policy_helper.ProtocolICMP,
+						network_policy_helper.ProtocolIPIP,
+					),
+				},
+			},
+			"port": schema.Int64Attribute{
+				MarkdownDescription: "connection's destination port, required when protocol is TCP or UDP",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(port_helper.MinPort, port_helper.MaxPort),
+				},
+			},
+			"icmp_type": schema.Int64Attribute{
+				MarkdownDescription: "connection's icmp type, only for ICMP, leave it unset to match rules allowing all icmp types only",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 255),
+				},
+			},
+			"icmp_code": schema.Int64Attribute{
+				MarkdownDescription: "connection's icmp code, only for ICMP with icmp_type, leave it unset to match rules allowing all codes only",
+				Optional:            true,
+				Validators: []validator.Int64{
+					int64validator.Between(0, 255),
+				},
+			},
+			"verdict": schema.StringAttribute{
+				MarkdownDescription: "ALLOW or DROP",
+				Computed:            true,
+			},
+			"matched": schema.BoolAttribute{
+				MarkdownDescription: "if the connection is allowed by global whitelist rules at every managed endpoint",
+				Computed:            true,
+			},
+			"egress_matched": schema.BoolAttribute{
+				MarkdownDescription: "if an egress rule matches the destination ip of the connection",
+				Computed:            true,
+			},
+			"egress_rule_index": schema.Int64Attribute{
+				MarkdownDescription: "index of the first egress rule matching the connection",
+				Computed:            true,
+			},
+			"egress_rule": schema.StringAttribute{
+				MarkdownDescription: "description of the first egress rule matching the connection",
+				Computed:            true,
+			},
+			"ingress_matched": schema.BoolAttribute{
+				MarkdownDescription: "if an ingress rule matches the source ip of the connection",
+				Computed:            true,
+			},
+			"ingress_rule_index": schema.Int64Attribute{
+				MarkdownDescription: "index of the first ingress rule matching the connection",
+				Computed:            true,
+			},
+			"ingress_rule": schema.StringAttribute{
+				MarkdownDescription: "description of the first ingress rule matching the connection",
+				Computed:            true,
+			},
+			"skipped_rules": schema.Int64Attribute{
+				MarkdownDescription: "number of selector and security group rules not evaluated",
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *DataSource) ValidateConfig(ctx context.Context, req datasource.ValidateConfigRequest, resp *datasource.ValidateConfigResponse) {
+	var data PolicyEvaluationDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() || data.Protocol.IsUnknown() {
+		return
+	}
+	protocol := data.Protocol.ValueString()
+	switch protocol {
+	case network_policy_helper.ProtocolTCP, network_policy_helper.ProtocolUDP:
+		if data.Port.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("port"), "Invalid port", fmt.Sprintf("port is required when protocol is %s", protocol))
+		}
+	default:
+		if !data.Port.IsNull() {
+			resp.Diagnostics.AddAttributeError(path.Root("port"), "Invalid port", fmt.Sprintf("port is not allowed when protocol is %s", protocol))
+		}
+	}
+	if protocol != network_policy_helper.ProtocolICMP && !data.ICMPType.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("icmp_type"), "Invalid icmp_type", fmt.Sprintf("icmp_type is not allowed when protocol is %s", protocol))
+	}
+	if !data.ICMPCode.IsNull() && data.ICMPType.IsNull() {
+		resp.Diagnostics.AddAttributeError(path.Root("icmp_code"), "Invalid icmp_code", "icmp_type is required when icmp_code is configured")
+	}
+	if data.SourceManaged.Equal(types.BoolValue(false)) && data.DestinationManaged.Equal(types.BoolValue(false)) {
+		resp.Diagnostics.AddAttributeError(path.Root("destination_managed"), "Invalid destination_managed",
+			"source_managed and destination_managed cannot be both false, as no endpoint enforces the global security policy")
+	}
+	for attr, ip := range map[string]types.String{"source_ip": data.SourceIP, "destination_ip": data.DestinationIP} {
+		if ip.IsNull() || ip.IsUnknown() {
+			continue
+		}
+		if _, err := netip.ParseAddr(ip.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root(attr), "Invalid "+attr, err.Error())
+		}
+	}
+}
+
+func (d *DataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state PolicyEvaluationDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	source, err := netip.ParseAddr(state.SourceIP.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source_ip"), "Invalid source_ip", err.Error())
+	}
+	destination, err := netip.ParseAddr(state.DestinationIP.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("destination_ip"), "Invalid destination_ip", err.Error())
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	gqlResp, _, err := d.client.DgqlApi.Raw(ctx, getGlobalPolicyDocument, "everouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": state.ServiceId.ValueString(),
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("read service global security policy failed", err.Error())
+		return
+	}
+	jServices := gqlResp.Get("everouteClusters").Array()
+	if len(jServices) == 0 {
+		resp.Diagnostics.AddAttributeError(path.Root("service_id"), "Everoute service not found",
+			fmt.Sprintf("everoute service %s not found", state.ServiceId.ValueString()))
+		return
+	}
+	if len(jServices) > 1 {
+		resp.Diagnostics.AddAttributeError(path.Root("service_id"), "Several everoute services found",
+			fmt.Sprintf("%d everoute services match %s", len(jServices), state.ServiceId.ValueString()))
+		return
+	}
+	jService := jServices[0]
+	services, diags := d.getServiceMembers(ctx, &jService)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	traffic := network_policy_helper.Traffic{
+		Protocol: state.Protocol.ValueString(),
+		Port:     state.Port.ValueInt64(),
+		ICMPType: -1,
+		ICMPCode: -1,
+	}
+	if !state.ICMPType.IsNull() {
+		traffic.ICMPType = state.ICMPType.ValueInt64()
+	}
+	if !state.ICMPCode.IsNull() {
+		traffic.ICMPCode = state.ICMPCode.ValueInt64()
+	}
+
+	var ingress, egress directionMatch
+	skipped := 0
+	if jService.Get("global_whitelist.enable").Bool() {
+		// egress rules of the source select the destination, ingress rules of the destination select the source
+		jegress := jService.Get("global_whitelist.egress")
+		jingress := jService.Get("global_whitelist.ingress")
+		var n int
+		egress, n = matchDirection(ctx, &jegress, services, destination, traffic)
+		skipped += n
+		ingress, n = matchDirection(ctx, &jingress, services, source, traffic)
+		skipped += n
+	}
+	sourceManaged := state.SourceManaged.IsNull() || state.SourceManaged.ValueBool()
+	destinationManaged := state.DestinationManaged.IsNull() || state.DestinationManaged.ValueBool()
+	state.Verdict = types.StringValue(verdict(jService.Get("global_default_action").String(), ingress, egress, sourceManaged, destinationManaged))
+	state.Matched = types.BoolValue((!sourceManaged || egress.matched) && (!destinationManaged || ingress.matched))
+	state.EgressMatched, state.EgressRuleIndex, state.EgressRule = egress.values()
+	state.IngressMatched, state.IngressRuleIndex, state.IngressRule = ingress.values()
+	state.SkippedRules = types.Int64Value(int64(skipped))
+
+	state.Id = types.StringValue(strconv.FormatInt(time.Now().Unix(), 10))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// matchDirection returns the first rule matching peer and traffic, and the number of rules not evaluable.
+func matchDirection(ctx context.Context, jrules *gjson.Result, services map[string][]gjson.Result, peer netip.Addr, traffic network_policy_helper.Traffic) (directionMatch, int) {
+	var match directionMatch
+	skipped := 0
+	for idx, jrule := range jrules.Array() {
+		jrule := jrule
+		matched, evaluable := network_policy_helper.MatchGqlResultRule(&jrule, services, peer, traffic)
+		if !evaluable {
+			skipped++
+		}
+		if !matched || match.matched {
+			continue
+		}
+		rule := network_policy_helper.ReadGqlResultToPeerRules(jrules)[idx]
+		match = directionMatch{matched: true, index: idx, rule: network_policy_helper.DescribePeerRule(ctx, &rule)}
+	}
+	return match, skipped
+}
+
+// verdict allows a connection only if every managed endpoint allows it, by a rule or by default action.
+func verdict(defaultAction string, ingress, egress directionMatch, sourceManaged, destinationManaged bool) string {
+	allowed := func(match directionMatch) bool {
+		return match.matched || defaultAction == "ALLOW"
+	}
+	if (sourceManaged && !allowed(egress)) || (destinationManaged && !allowed(ingress)) {
+		return "DROP"
+	}
+	return "ALLOW"
+}
+
+func (m directionMatch) values() (types.Bool, types.Int64, types.String) {
+	if !m.matched {
+		return types.BoolValue(false), types.Int64Null(), types.StringNull()
+	}
+	return types.BoolValue(true), types.Int64Value(int64(m.index)), types.StringValue(m.rule)
+}
+
+// getServiceMembers returns members of network services referenced by global whitelist rules, by service id.
+func (d *DataSource) getServiceMembers(ctx context.Context, jService *gjson.Result) (map[string][]gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	members := make(map[string][]gjson.Result)
+	ids := make([]string, 0)
+	for _, p := range []string{"global_whitelist.ingress.#.services", "global_whitelist.egress.#.services"} {
+		for _, jservices := range jService.Get(p).Array() {
+			for _, js := range jservices.Array() {
+				ids = append(ids, js.String())
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return members, diags
+	}
+	gqlResp, _, err := d.client.DgqlApi.Raw(ctx, getNetworkServicesDocument, "networkPolicyRuleServices", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id_in": ids,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError("read network services failed", err.Error())
+		return members, diags
+	}
+	for _, js := range gqlResp.Get("networkPolicyRuleServices").Array() {
+		members[js.Get("id").String()] = js.Get("members").Array()
+	}
+	return members, diags
+}
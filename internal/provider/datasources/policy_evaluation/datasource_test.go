@@ -0,0 +1,68 @@
+package policy_evaluation
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+func TestVerdict(t *testing.T) {
+	matched := directionMatch{matched: true}
+	cases := []struct {
+		name               string
+		defaultAction      string
+		ingress, egress    directionMatch
+		sourceManaged      bool
+		destinationManaged bool
+		want               string
+	}{
+		{"both sides matched", "DROP", matched, matched, true, true, "ALLOW"},
+		{"ingress only", "DROP", matched, directionMatch{}, true, true, "DROP"},
+		{"egress only", "DROP", directionMatch{}, matched, true, true, "DROP"},
+		{"ingress of unmanaged source", "DROP", matched, directionMatch{}, false, true, "ALLOW"},
+		{"egress to unmanaged destination", "DROP", directionMatch{}, matched, true, false, "ALLOW"},
+		{"default allow", "ALLOW", directionMatch{}, directionMatch{}, true, true, "ALLOW"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := verdict(c.defaultAction, c.ingress, c.egress, c.sourceManaged, c.destinationManaged); got != c.want {
+				t.Errorf("verdict = %s, want %s", got, c.want)
+			}
+		})
+	}
+}
+
+func TestMatchDirection(t *testing.T) {
+	jrules := gjson.Parse(`[
+		{"type": "SELECTOR", "selector": [{"id": "label-1", "key": "env", "value": "prod"}], "except_ip_block": [], "services": [], "ports": []},
+		{"type": "IP_BLOCK", "ip_block": "10.0.0.0/24", "except_ip_block": ["10.0.0.8/29"], "services": [], "ports": [{"protocol": "TCP", "port": "5432"}]},
+		{"type": "IP_BLOCK", "ip_block": "10.0.0.9", "except_ip_block": [], "services": [], "ports": []}
+	]`)
+	traffic := network_policy_helper.Traffic{Protocol: network_policy_helper.ProtocolTCP, Port: 5432, ICMPType: -1, ICMPCode: -1}
+	cases := []struct {
+		peer      string
+		wantMatch bool
+		wantIndex int
+	}{
+		{"10.0.0.1", true, 1},
+		{"10.0.0.9", true, 2},
+		{"10.0.1.1", false, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.peer, func(t *testing.T) {
+			match, skipped := matchDirection(context.Background(), &jrules, nil, netip.MustParseAddr(c.peer), traffic)
+			if match.matched != c.wantMatch || match.index != c.wantIndex {
+				t.Errorf("match = %+v, want matched %v at %d", match, c.wantMatch, c.wantIndex)
+			}
+			if c.wantMatch && match.rule == "" {
+				t.Error("matched rule is not described")
+			}
+			if skipped != 1 {
+				t.Errorf("skipped = %d, want the selector rule skipped", skipped)
+			}
+		})
+	}
+}
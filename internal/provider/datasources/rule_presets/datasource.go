@@ -0,0 +1,329 @@
+package rule_presets
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+)
+
+const (
+	presetDNS        = "dns"
+	presetNTP        = "ntp"
+	presetDHCP       = "dhcp"
+	presetCloudtower = "cloudtower"
+	presetController = "everoute_controller"
+)
+
+// presetRule allows protocols from (ingress) or to (egress) peers of a preset.
+type presetRule struct {
+	egress   bool
+	tcp      bool
+	tcpPorts string
+	udp      bool
+	udpPorts string
+	// defaultCIDRs are peers of the rule when the preset has no cidrs configured or resolved
+	defaultCIDRs []string
+}
+
+// presets are the vetted rules of each preset.
+var presets = map[string][]presetRule{
+	presetDNS: {
+		{egress: true, tcp: true, tcpPorts: "53", udp: true, udpPorts: "53"},
+	},
+	presetNTP: {
+		{egress: true, udp: true, udpPorts: "123"},
+	},
+	presetDHCP: {
+		// clients discover servers by broadcast before they know them
+		{egress: true, udp: true, udpPorts: "67", defaultCIDRs: []string{"255.255.255.255"}},
+		{udp: true, udpPorts: "68", defaultCIDRs: []string{"0.0.0.0/0"}},
+	},
+	presetCloudtower: {
+		{tcp: true},
+		{egress: true, tcp: true},
+	},
+	presetController: {
+		{tcp: true},
+		{egress: true, tcp: true},
+	},
+}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &DataSource{}
+
+func NewDataSource() datasource.DataSource {
+	return &DataSource{}
+}
+
+// DataSource defines the data source implementation.
+type DataSource struct {
+	client *everoute.Client
+}
+
+// RulePresetsDataSourceModel describes the data source data model.
+type RulePresetsDataSourceModel struct {
+	Id        types.String      `tfsdk:"id"`
+	ServiceId types.String      `tfsdk:"service_id"`
+	Presets   []PresetModel     `tfsdk:"presets"`
+	Ingress   []PresetRuleModel `tfsdk:"ingress"`
+	Egress    []PresetRuleModel `tfsdk:"egress"`
+}
+
+type PresetModel struct {
+	Name  types.String `tfsdk:"name"`
+	CIDRs types.List   `tfsdk:"cidrs"`
+}
+
+// PresetRuleModel is the convenience form of network_policy_helper.NetworkPolicyRuleModel,
+// assignable to rules of global security policy and isolation policy.
+type PresetRuleModel struct {
+	IPBlock       types.String `tfsdk:"ip_block"`
+	ExceptIPBlock types.List   `tfsdk:"except_ip_block"`
+	TCPEnabled    types.Bool   `tfsdk:"tcp_enabled"`
+	TCPPorts      types.String `tfsdk:"tcp_ports"`
+	UDPEnabled    types.Bool   `tfsdk:"udp_enabled"`
+	UDPPorts      types.String `tfsdk:"udp_ports"`
+	ICMPEnabled   types.Bool   `tfsdk:"icmp_enabled"`
+	ServiceIds    types.List   `tfsdk:"network_service_ids"`
+}
+
+func (d *DataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rule_presets"
+}
+
+func (d *DataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "vetted network policy rules of common traffic by preset name:\n" +
+			"- `dns`: egress tcp and udp 53 to cidrs\n" +
+			"- `ntp`: egress udp 123 to cidrs\n" +
+			"- `dhcp`: egress udp 67 to cidrs, broadcast by default, and ingress udp 68 from cidrs, any by default\n" +
+			"- `cloudtower`: ingress and egress tcp from and to cidrs, the configured cloudtower server by default\n" +
+			"- `everoute_controller`: ingress and egress tcp from and to cidrs, controller instances of service_id by default",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "presets' identifier",
+				Computed:            true,
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service whose controller instances are peers of everoute_controller preset",
+				Optional:            true,
+			},
+			"presets": schema.ListNestedAttribute{
+				MarkdownDescription: "presets to return rules of",
+				Required:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							MarkdownDescription: "preset's name, valid value: dns, ntp, dhcp, cloudtower, everoute_controller",
+							Required:            true,
+							Validators: []validator.String{
+								stringvalidator.OneOf(presetDNS, presetNTP, presetDHCP, presetCloudtower, presetController),
+							},
+						},
+						"cidrs": schema.ListAttribute{
+							MarkdownDescription: "peers of the preset's rules, cidrs or ips, required by dns and ntp",
+							ElementType:         types.StringType,
+							Optional:            true,
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+								listvalidator.ValueStringsAre(ip_helper.GetIPBlockValidator()),
+							},
+						},
+					},
+				},
+			},
+			"ingress": schema.ListNestedAttribute{
+				MarkdownDescription: "ingress rules of the presets",
+				Computed:            true,
+				NestedObject:        presetRuleSchema(),
+			},
+			"egress": schema.ListNestedAttribute{
+				MarkdownDescription: "egress rules of the presets",
+				Computed:            true,
+				NestedObject:        presetRuleSchema(),
+			},
+		},
+	}
+}
+
+func presetRuleSchema() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"ip_block": schema.StringAttribute{
+				MarkdownDescription: "rule included ip block",
+				Computed:            true,
+			},
+			"except_ip_block": schema.ListAttribute{
+				MarkdownDescription: "rule excluded ip block",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+			"tcp_enabled": schema.BoolAttribute{
+				MarkdownDescription: "if tcp is allowed",
+				Computed:            true,
+			},
+			"tcp_ports": schema.StringAttribute{
+				MarkdownDescription: "tcp ports allowed, empty for all ports",
+				Computed:            true,
+			},
+			"udp_enabled": schema.BoolAttribute{
+				MarkdownDescription: "if udp is allowed",
+				Computed:            true,
+			},
+			"udp_ports": schema.StringAttribute{
+				MarkdownDescription: "udp ports allowed, empty for all ports",
+				Computed:            true,
+			},
+			"icmp_enabled": schema.BoolAttribute{
+				MarkdownDescription: "if icmp is allowed",
+				Computed:            true,
+			},
+			"network_service_ids": schema.ListAttribute{
+				MarkdownDescription: "ids of network services allowed",
+				ElementType:         types.StringType,
+				Computed:            true,
+			},
+		},
+	}
+}
+
+func (d *DataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Data Source Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	d.client = client
+}
+
+func (d *DataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state RulePresetsDataSourceModel
+
+	// Read Terraform configuration data into the model
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Ingress = make([]PresetRuleModel, 0)
+	state.Egress = make([]PresetRuleModel, 0)
+	for idx, preset := range state.Presets {
+		presetPath := path.Root("presets").AtListIndex(idx)
+		cidrs, diags := d.presetCIDRs(ctx, &state, &preset, presetPath)
+		resp.Diagnostics.Append(diags...)
+		if diags.HasError() {
+			continue
+		}
+		for _, p := range presets[preset.Name.ValueString()] {
+			peers := cidrs
+			if len(peers) == 0 {
+				peers = p.defaultCIDRs
+			}
+			for _, cidr := range peers {
+				rule := PresetRuleModel{
+					IPBlock:       types.StringValue(cidr),
+					ExceptIPBlock: types.ListValueMust(types.StringType, []attr.Value{}),
+					TCPEnabled:    types.BoolValue(p.tcp),
+					TCPPorts:      types.StringValue(p.tcpPorts),
+					UDPEnabled:    types.BoolValue(p.udp),
+					UDPPorts:      types.StringValue(p.udpPorts),
+					ICMPEnabled:   types.BoolValue(false),
+					ServiceIds:    types.ListValueMust(types.StringType, []attr.Value{}),
+				}
+				if p.egress {
+					state.Egress = append(state.Egress, rule)
+				} else {
+					state.Ingress = append(state.Ingress, rule)
+				}
+			}
+		}
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Id = types.StringValue(strconv.FormatInt(time.Now().Unix(), 10))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// presetCIDRs returns cidrs configured or resolved for a preset, empty for presets with default cidrs.
+func (d *DataSource) presetCIDRs(ctx context.Context, state *RulePresetsDataSourceModel, preset *PresetModel, presetPath path.Path) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	cidrs := make([]string, 0)
+	if !preset.CIDRs.IsNull() {
+		diags.Append(preset.CIDRs.ElementsAs(ctx, &cidrs, false)...)
+		return cidrs, diags
+	}
+	switch name := preset.Name.ValueString(); name {
+	case presetDNS, presetNTP:
+		diags.AddAttributeError(presetPath.AtName("cidrs"), "Invalid preset", fmt.Sprintf("cidrs is required by preset %s", name))
+	case presetCloudtower:
+		addrs, err := d.client.ServerAddrs(ctx)
+		if err != nil {
+			diags.AddAttributeError(presetPath.AtName("cidrs"), "Unable to resolve cloudtower server",
+				fmt.Sprintf("Failed to resolve cloudtower server %s: %s, configure cidrs instead", d.client.Server(), err))
+		}
+		for _, addr := range addrs {
+			cidrs = append(cidrs, netip.PrefixFrom(addr, addr.BitLen()).String())
+		}
+	case presetController:
+		if state.ServiceId.IsNull() {
+			diags.AddAttributeError(presetPath.AtName("cidrs"), "Invalid preset",
+				fmt.Sprintf("cidrs or service_id is required by preset %s", name))
+			return cidrs, diags
+		}
+		gqlResp, _, err := d.client.DgqlApi.Raw(ctx, getControllerInstancesDocument, "everouteClusters", map[string]interface{}{
+			"where": map[string]interface{}{
+				"id": state.ServiceId.ValueString(),
+			},
+		}, nil)
+		if err != nil {
+			diags.AddError("read everoute controller instances failed", err.Error())
+			return cidrs, diags
+		}
+		jService := gqlResp.Get("everouteClusters.0")
+		if !jService.Exists() {
+			diags.AddAttributeError(path.Root("service_id"), "Everoute service not found",
+				fmt.Sprintf("everoute service %s not found", state.ServiceId.ValueString()))
+			return cidrs, diags
+		}
+		for _, jc := range jService.Get("controller_instances").Array() {
+			if block, err := ip_helper.NormalizeIPBlock(jc.Get("ipAddr").String()); err == nil {
+				cidrs = append(cidrs, block)
+			}
+		}
+		if len(cidrs) == 0 {
+			diags.AddAttributeError(path.Root("service_id"), "Invalid preset",
+				fmt.Sprintf("everoute service %s has no controller instance", state.ServiceId.ValueString()))
+		}
+	}
+	return cidrs, diags
+}
@@ -0,0 +1,12 @@
+package rule_presets
+
+var getControllerInstancesDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	  controller_instances {
+		ipAddr
+	  }
+	}
+  }
+`
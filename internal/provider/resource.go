@@ -5,12 +5,24 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/everoute_service"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/everoute_service_association"
 	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/global_security_policy"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/global_security_policy_rule"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/isolation_policy"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/network_service"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/security_group"
+	"github.com/smartxworks/terraform-provider-everoute/internal/provider/resources/security_policy"
 )
 
 func (p *EverouteProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		func() resource.Resource { return &everoute_service.Resource{} },
+		func() resource.Resource { return &everoute_service_association.Resource{} },
 		func() resource.Resource { return &global_security_policy.Resource{} },
+		func() resource.Resource { return &security_policy.Resource{} },
+		func() resource.Resource { return &isolation_policy.Resource{} },
+		func() resource.Resource { return &security_group.Resource{} },
+		func() resource.Resource { return &network_service.Resource{} },
+		func() resource.Resource { return &global_security_policy_rule.Resource{} },
 	}
 }
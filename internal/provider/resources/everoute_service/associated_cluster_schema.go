@@ -14,8 +14,8 @@ type AssociatedClusterModel struct {
 
 func associatedClusterSchema() schema.Attribute {
 	return schema.ListNestedAttribute{
-		MarkdownDescription: "elf cluster's schema",
-		Required:            true,
+		MarkdownDescription: "elf cluster's schema, leave it unset to manage associations by everoute_service_association",
+		Optional:            true,
 		NestedObject: schema.NestedAttributeObject{
 			Attributes: map[string]schema.Attribute{
 				// TODO: can use both id or name as identifier
@@ -467,34 +467,40 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
-	clusterParams, vdsesParam := diffAssociatedClusters(&plan.AssociatedCluster, &state.AssociatedCluster)
-
 	id := state.Id.ValueString()
 
-	_, headers, err := r.client.DgqlApi.Raw(ctx, associatedClusterDocument, "updateEverouteClusterAssociation", map[string]interface{}{
-		"where": map[string]interface{}{
-			"id": id,
-		},
-		"data": map[string]interface{}{
-			"agent_elf_clusters": clusterParams,
-			"agent_elf_vdses":    vdsesParam,
-		},
-	}, nil)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Update everoute service failed",
-			fmt.Sprintf("Unable to update everoute service, got error: %s", err),
-		)
-		return
-	}
-	taskid := headers.Get("X-Task-Id")
-	err = utils.WaitTask(ctx, r.client.Api, &taskid, 10*time.Second)
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Update everoute service failed",
-			fmt.Sprintf("Task not complete successfully, got error: %s", err),
-		)
-		return
+	// associated_cluster unset means associations are managed elsewhere, leave them untouched
+	if plan.AssociatedCluster != nil {
+		clusterParams, vdsesParam := diffAssociatedClusters(&plan.AssociatedCluster, &state.AssociatedCluster)
+
+		unlock := r.client.LockService(id)
+		_, headers, err := r.client.DgqlApi.Raw(ctx, associatedClusterDocument, "updateEverouteClusterAssociation", map[string]interface{}{
+			"where": map[string]interface{}{
+				"id": id,
+			},
+			"data": map[string]interface{}{
+				"agent_elf_clusters": clusterParams,
+				"agent_elf_vdses":    vdsesParam,
+			},
+		}, nil)
+		if err != nil {
+			unlock()
+			resp.Diagnostics.AddError(
+				"Update everoute service failed",
+				fmt.Sprintf("Unable to update everoute service, got error: %s", err),
+			)
+			return
+		}
+		taskid := headers.Get("X-Task-Id")
+		err = utils.WaitTask(ctx, r.client.Api, &taskid, 10*time.Second)
+		unlock()
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Update everoute service failed",
+				fmt.Sprintf("Task not complete successfully, got error: %s", err),
+			)
+			return
+		}
 	}
 
 	// re-read the everoute service after update
@@ -674,7 +680,11 @@ func readGqlResultToState(input *gjson.Result, state *EverouteServiceResourceMod
 		acname := ac.Get("name").String()
 		clIdNameMap[acid] = acname
 	}
-	tac := make([]AssociatedClusterModel, 0)
+	// keep associated_cluster null when it is not managed by this resource
+	var tac []AssociatedClusterModel
+	if state.AssociatedCluster != nil {
+		tac = make([]AssociatedClusterModel, 0)
+	}
 	for _, ac := range state.AssociatedCluster {
 		acid := ac.Id.ValueString()
 		if ac, ok := clIdNameMap[acid]; ok {
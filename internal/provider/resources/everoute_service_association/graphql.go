@@ -0,0 +1,31 @@
+package everoute_service_association
+
+var getServiceAssociationDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	  agent_elf_clusters {
+		id
+		name
+	  }
+	  agent_elf_vdses {
+		id
+		name
+		cluster {
+			id
+		}
+	  }
+	}
+  }
+`
+
+var updateServiceAssociationDocument = `
+mutation updateEverouteClusterAssociation(
+	$where: EverouteClusterWhereUniqueInput!
+	$data: EverouteClusterUpdateInput!
+  ) {
+	updateEverouteCluster(where: $where, data: $data) {
+	  id
+	}
+  }
+`
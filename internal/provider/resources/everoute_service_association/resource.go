@@ -0,0 +1,496 @@
+package everoute_service_association
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/cluster"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vds"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/tidwall/gjson"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Resource{}
+var _ resource.ResourceWithImportState = &Resource{}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+// Resource defines the resource implementation.
+type Resource struct {
+	client *everoute.Client
+}
+
+// EverouteServiceAssociationResourceModel describes the resource data model.
+type EverouteServiceAssociationResourceModel struct {
+	Id          types.String         `tfsdk:"id"`
+	ServiceId   types.String         `tfsdk:"service_id"`
+	ClusterId   types.String         `tfsdk:"cluster_id"`
+	ClusterName types.String         `tfsdk:"cluster_name"`
+	VDSes       []AssociatedVdsModel `tfsdk:"vdses"`
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_service_association"
+}
+
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "associate one elf cluster with an everoute service, only connect or disconnect its own cluster, " +
+			"should not be used together with everoute_service's associated_cluster on the same service",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "identifier, formatted as service_id/cluster_id",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cluster_id": schema.StringAttribute{
+				MarkdownDescription: "elf cluster's id",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"cluster_name": schema.StringAttribute{
+				MarkdownDescription: "elf cluster's name",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"vdses": associatedVdsSchema(),
+		},
+	}
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *EverouteServiceAssociationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serviceId := data.ServiceId.ValueString()
+	clusterId := data.ClusterId.ValueString()
+
+	// check cluster and vdses exist before touching the everoute service
+	resp.Diagnostics.Append(r.checkClusterAndVdses(data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock := r.client.LockService(serviceId)
+	defer unlock()
+
+	service, diags := getServiceAssociationGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		resp.Diagnostics.AddError(
+			"Create everoute service association failed",
+			fmt.Sprintf("Cannot find everoute service %s", serviceId),
+		)
+		return
+	}
+	if isClusterAssociated(service, clusterId) {
+		resp.Diagnostics.AddError(
+			"Create everoute service association failed",
+			fmt.Sprintf("Cluster %s is already associated with everoute service %s, import it instead", clusterId, serviceId),
+		)
+		return
+	}
+
+	vdsIds := otherClusterVdsIds(service, clusterId)
+	for _, v := range data.VDSes {
+		vdsIds = append(vdsIds, v.Id.ValueString())
+	}
+	resp.Diagnostics.Append(r.updateAssociation(ctx, serviceId, map[string]interface{}{
+		"connect": []map[string]interface{}{
+			{"id": clusterId},
+		},
+	}, vdsIds)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Id = types.StringValue(buildId(serviceId, clusterId))
+	service, diags = getServiceAssociationGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil || !readGqlResultToState(service, data) {
+		resp.Diagnostics.AddError(
+			"Create everoute service association failed",
+			fmt.Sprintf("Cluster %s is not associated with everoute service %s after update", clusterId, serviceId),
+		)
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *EverouteServiceAssociationResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags := getServiceAssociationGqlResult(ctx, r.client, data.ServiceId.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// service deleted or cluster disassociated outside terraform
+	if service == nil || !readGqlResultToState(service, data) {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state *EverouteServiceAssociationResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serviceId := state.ServiceId.ValueString()
+	clusterId := state.ClusterId.ValueString()
+
+	resp.Diagnostics.Append(r.checkClusterAndVdses(plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock := r.client.LockService(serviceId)
+	defer unlock()
+
+	service, diags := getServiceAssociationGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if service == nil {
+		resp.Diagnostics.AddError(
+			"Update everoute service association failed",
+			fmt.Sprintf("Cannot find everoute service %s", serviceId),
+		)
+		return
+	}
+
+	// only vdses can be updated in place, keep vdses of other clusters untouched
+	vdsIds := otherClusterVdsIds(service, clusterId)
+	for _, v := range plan.VDSes {
+		vdsIds = append(vdsIds, v.Id.ValueString())
+	}
+	resp.Diagnostics.Append(r.updateAssociation(ctx, serviceId, nil, vdsIds)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	service, diags = getServiceAssociationGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.Id = state.Id
+	if service == nil || !readGqlResultToState(service, plan) {
+		resp.Diagnostics.AddError(
+			"Update everoute service association failed",
+			fmt.Sprintf("Cluster %s is not associated with everoute service %s after update", clusterId, serviceId),
+		)
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *EverouteServiceAssociationResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	serviceId := data.ServiceId.ValueString()
+	clusterId := data.ClusterId.ValueString()
+
+	unlock := r.client.LockService(serviceId)
+	defer unlock()
+
+	service, diags := getServiceAssociationGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// nothing to disassociate if service is already deleted or cluster is disassociated
+	if service == nil || !isClusterAssociated(service, clusterId) {
+		return
+	}
+
+	resp.Diagnostics.Append(r.updateAssociation(ctx, serviceId, map[string]interface{}{
+		"disconnect": []map[string]interface{}{
+			{"id": clusterId},
+		},
+	}, otherClusterVdsIds(service, clusterId))...)
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	serviceId, clusterId, ok := strings.Cut(req.ID, "/")
+	if !ok || serviceId == "" || clusterId == "" {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: service_id/cluster_id. Got: %q", req.ID),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service_id"), serviceId)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_id"), clusterId)...)
+}
+
+// checkClusterAndVdses makes sure cluster exists and all vdses belong to it, fill their names into data.
+func (r *Resource) checkClusterAndVdses(data *EverouteServiceAssociationResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	clusterId := data.ClusterId.ValueString()
+	gcp := cluster.NewGetClustersParams()
+	gcp.RequestBody = &models.GetClustersRequestBody{
+		Where: &models.ClusterWhereInput{
+			ID: &clusterId,
+		},
+	}
+	cs, err := r.client.Api.Cluster.GetClusters(gcp)
+	if err != nil {
+		diags.AddError("Failed to check associated cluster", fmt.Sprintf("Unable to check cluster id, got error: %s", err))
+		return diags
+	}
+	if len(cs.Payload) == 0 {
+		diags.AddError("Failed to check associated cluster", fmt.Sprintf("Cluster id %s not exist", clusterId))
+		return diags
+	}
+	data.ClusterName = types.StringValue(*cs.Payload[0].Name)
+
+	if len(data.VDSes) == 0 {
+		return diags
+	}
+	vdsIds := make([]string, 0, len(data.VDSes))
+	for _, v := range data.VDSes {
+		vdsIds = append(vdsIds, v.Id.ValueString())
+	}
+	gvdsp := vds.NewGetVdsesParams()
+	gvdsp.RequestBody = &models.GetVdsesRequestBody{
+		Where: &models.VdsWhereInput{
+			IDIn: vdsIds,
+			Cluster: &models.ClusterWhereInput{
+				ID: &clusterId,
+			},
+		},
+	}
+	vdses, err := r.client.Api.Vds.GetVdses(gvdsp)
+	if err != nil {
+		diags.AddError("Failed to check associated vds", fmt.Sprintf("Unable to check vds, got error: %s", err))
+		return diags
+	}
+	vdsNameMap := make(map[string]string)
+	for _, v := range vdses.Payload {
+		vdsNameMap[*v.ID] = *v.Name
+	}
+	for idx, v := range data.VDSes {
+		name, ok := vdsNameMap[v.Id.ValueString()]
+		if !ok {
+			diags.AddError("Failed to check associated vds", fmt.Sprintf("Vds %s not exist or not belongs to cluster %s", v.Id.ValueString(), clusterId))
+			continue
+		}
+		data.VDSes[idx].Name = types.StringValue(name)
+	}
+	return diags
+}
+
+func (r *Resource) updateAssociation(ctx context.Context, serviceId string, clusterParams map[string]interface{}, vdsIds []string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	vdsesParam := make([]map[string]interface{}, 0, len(vdsIds))
+	for _, vdsId := range vdsIds {
+		vdsesParam = append(vdsesParam, map[string]interface{}{
+			"id": vdsId,
+		})
+	}
+	data := map[string]interface{}{
+		"agent_elf_vdses": map[string]interface{}{
+			"set": vdsesParam,
+		},
+	}
+	if clusterParams != nil {
+		data["agent_elf_clusters"] = clusterParams
+	}
+	_, headers, err := r.client.DgqlApi.Raw(ctx, updateServiceAssociationDocument, "updateEverouteClusterAssociation", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+		"data": data,
+	}, nil)
+	if err != nil {
+		diags.AddError("Failed to update everoute service association", fmt.Sprintf("Unable to update everoute service association, got error: %s", err))
+		return diags
+	}
+	taskid := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskid, 10*time.Second)
+	if err != nil {
+		diags.AddError("Failed to update everoute service association", fmt.Sprintf("Task not complete successfully, got error: %s", err))
+	}
+	return diags
+}
+
+// getServiceAssociationGqlResult returns nil result without error when service not found.
+func getServiceAssociationGqlResult(ctx context.Context, client *everoute.Client, serviceId string) (*gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getServiceAssociationDocument, "everouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError("Failed to read everoute service", fmt.Sprintf("Unable to read everoute service, got error: %s", err))
+		return nil, diags
+	}
+	service := result.Get("everouteClusters.0")
+	if service.Type == gjson.Null {
+		return nil, diags
+	}
+	return &service, diags
+}
+
+func isClusterAssociated(service *gjson.Result, clusterId string) bool {
+	for _, aec := range service.Get("agent_elf_clusters").Array() {
+		if aec.Get("id").String() == clusterId {
+			return true
+		}
+	}
+	return false
+}
+
+// otherClusterVdsIds returns associated vdses not belongs to given cluster.
+func otherClusterVdsIds(service *gjson.Result, clusterId string) []string {
+	vdsIds := make([]string, 0)
+	for _, v := range service.Get("agent_elf_vdses").Array() {
+		if v.Get("cluster.id").String() != clusterId {
+			vdsIds = append(vdsIds, v.Get("id").String())
+		}
+	}
+	return vdsIds
+}
+
+// readGqlResultToState returns false if cluster is not associated with service.
+func readGqlResultToState(input *gjson.Result, state *EverouteServiceAssociationResourceModel) bool {
+	clusterId := state.ClusterId.ValueString()
+	associated := false
+	for _, aec := range input.Get("agent_elf_clusters").Array() {
+		if aec.Get("id").String() == clusterId {
+			associated = true
+			state.ClusterName = types.StringValue(aec.Get("name").String())
+		}
+	}
+	if !associated {
+		return false
+	}
+	state.ServiceId = types.StringValue(input.Get("id").String())
+	state.Id = types.StringValue(buildId(state.ServiceId.ValueString(), clusterId))
+
+	vdsNameMap := make(map[string]string)
+	for _, v := range input.Get("agent_elf_vdses").Array() {
+		if v.Get("cluster.id").String() == clusterId {
+			vdsNameMap[v.Get("id").String()] = v.Get("name").String()
+		}
+	}
+	// keep vdses order in state, append vdses associated outside terraform
+	vdses := make([]AssociatedVdsModel, 0, len(vdsNameMap))
+	for _, v := range state.VDSes {
+		vdsId := v.Id.ValueString()
+		if name, ok := vdsNameMap[vdsId]; ok {
+			vdses = append(vdses, AssociatedVdsModel{
+				Id:   types.StringValue(vdsId),
+				Name: types.StringValue(name),
+			})
+			delete(vdsNameMap, vdsId)
+		}
+	}
+	for _, v := range input.Get("agent_elf_vdses").Array() {
+		vdsId := v.Get("id").String()
+		if name, ok := vdsNameMap[vdsId]; ok {
+			vdses = append(vdses, AssociatedVdsModel{
+				Id:   types.StringValue(vdsId),
+				Name: types.StringValue(name),
+			})
+		}
+	}
+	state.VDSes = vdses
+	return true
+}
+
+func buildId(serviceId string, clusterId string) string {
+	return fmt.Sprintf("%s/%s", serviceId, clusterId)
+}
@@ -0,0 +1,35 @@
+package everoute_service_association
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type AssociatedVdsModel struct {
+	Id   types.String `tfsdk:"id"`
+	Name types.String `tfsdk:"name"`
+}
+
+func associatedVdsSchema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: "vdses of the cluster handled by everoute service",
+		Required:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"id": schema.StringAttribute{
+					MarkdownDescription: "elf vds's id",
+					Required:            true,
+				},
+				"name": schema.StringAttribute{
+					MarkdownDescription: "elf vds's name",
+					Computed:            true,
+				},
+			},
+		},
+		Validators: []validator.List{
+			listvalidator.UniqueValues(),
+		},
+	}
+}
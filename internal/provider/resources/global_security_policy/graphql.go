@@ -5,22 +5,47 @@ query getEverouteClusters($where: EverouteClusterWhereInput) {
 	everouteClusters(where: $where, first: 1) {
 	  id
 	  global_default_action
+	  controller_instances {
+		ipAddr
+	  }
 	  global_whitelist {
 		enable
 		egress {
+		  type
 		  ip_block
 		  except_ip_block
+		  services
+		  selector {
+			id
+			key
+			value
+		  }
+		  security_group_id
 		  ports {
 			port
 			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
 		  }
 		}
 		ingress {
+		  type
 		  ip_block
 		  except_ip_block
+		  services
+		  selector {
+			id
+			key
+			value
+		  }
+		  security_group_id
 		  ports {
 			port
 			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
 		  }
 		}
 	  }
@@ -29,6 +54,15 @@ query getEverouteClusters($where: EverouteClusterWhereInput) {
   
 `
 
+var getEverouteClusterIdsDocument = `
+query getEverouteClusterIds($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where) {
+	  id
+	  name
+	}
+  }
+`
+
 var updateGlobalWhiteListDocument = `
 mutation updateEverouteClusterGlobalAction(
 	$where: EverouteClusterWhereUniqueInput!
@@ -0,0 +1,147 @@
+package global_security_policy
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+// managementPath is an address which must stay reachable on tcp ports when default action is DROP,
+// empty ports mean all ports.
+type managementPath struct {
+	name  string
+	block netip.Prefix
+	ports string
+}
+
+func (p managementPath) String() string {
+	if p.ports == "" {
+		return fmt.Sprintf("%s (%s)", p.name, p.block)
+	}
+	return fmt.Sprintf("%s (%s tcp port %s)", p.name, p.block, p.ports)
+}
+
+// checkLockout reports management paths not allowed by ingress or egress rules when default action is DROP,
+// as errors unless lockout risk is acknowledged.
+func (r *Resource) checkLockout(ctx context.Context, plan *GlobalSecurityPolicyResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if plan.DefaultAction.IsUnknown() || plan.DefaultAction.ValueString() != "DROP" || plan.Enable.IsUnknown() {
+		return diags
+	}
+	// provider is not configured yet
+	if r.client == nil {
+		return diags
+	}
+
+	var current *gjson.Result
+	if !plan.ServiceId.IsUnknown() {
+		current, diags = getGlobalWhitelistGqlResult(ctx, r.client, plan.ServiceId.ValueString())
+		if diags.HasError() {
+			return diags
+		}
+	}
+	paths, d := r.managementPaths(ctx, plan, current)
+	diags.Append(d...)
+
+	for _, direction := range []string{"ingress", "egress"} {
+		rules := plan.Ingress
+		if direction == "egress" {
+			rules = plan.Egress
+		}
+		// unset rules keep rules managed outside
+		if rules == nil && current != nil {
+			jrules := current.Get("global_whitelist." + direction)
+			rules = network_policy_helper.ReadGqlResultToPeerRules(&jrules)
+		}
+		// disabled whitelist allows nothing
+		if !plan.Enable.ValueBool() {
+			rules = nil
+		}
+
+		blocked := make([]string, 0)
+		for _, p := range paths {
+			if allowed, known := network_policy_helper.AllowsIPBlock(ctx, rules, p.block, p.ports); !allowed && known {
+				blocked = append(blocked, p.String())
+			}
+		}
+		if len(blocked) == 0 {
+			continue
+		}
+		detail := fmt.Sprintf("default_action DROP blocks %s tcp traffic of management paths not allowed by any ip block %s rule: %s",
+			direction, direction, strings.Join(blocked, ", "))
+		if plan.AcknowledgeLockoutRisk.ValueBool() {
+			diags.AddAttributeWarning(path.Root("default_action"), "Management paths may be locked out", detail)
+		} else {
+			diags.AddAttributeError(path.Root("default_action"), "Management paths may be locked out",
+				detail+", allow them by "+direction+" rules or set acknowledge_lockout_risk to true")
+		}
+	}
+	return diags
+}
+
+// lockoutInputsChanged reports if values deciding lockout of an existing resource are planned to change,
+// unchanged ones are not checked again, which would resolve the cloudtower server and query the service on every plan.
+func lockoutInputsChanged(ctx context.Context, req resource.ModifyPlanRequest) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if req.State.Raw.IsNull() {
+		return true, diags
+	}
+	for _, name := range []string{"enable", "default_action", "ingress", "egress", "protected_cidrs"} {
+		var planned, prior attr.Value
+		diags.Append(req.Plan.GetAttribute(ctx, path.Root(name), &planned)...)
+		diags.Append(req.State.GetAttribute(ctx, path.Root(name), &prior)...)
+		if diags.HasError() {
+			return false, diags
+		}
+		if !planned.Equal(prior) {
+			return true, diags
+		}
+	}
+	return false, diags
+}
+
+// managementPaths collects cloudtower server on the port the provider connects to, controller instances
+// of the service and protected cidrs on all ports, paths not known yet are skipped.
+func (r *Resource) managementPaths(ctx context.Context, plan *GlobalSecurityPolicyResourceModel, current *gjson.Result) ([]managementPath, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	paths := make([]managementPath, 0)
+
+	addrs, err := r.client.ServerAddrs(ctx)
+	if err != nil {
+		diags.AddAttributeWarning(path.Root("default_action"), "Unable to check cloudtower server lockout",
+			fmt.Sprintf("Failed to resolve cloudtower server %s: %s", r.client.Server(), err))
+	}
+	for _, addr := range addrs {
+		paths = append(paths, managementPath{name: "cloudtower server", block: netip.PrefixFrom(addr, addr.BitLen()), ports: r.client.ServerPort()})
+	}
+
+	if current != nil {
+		for _, jc := range current.Get("controller_instances").Array() {
+			if block, err := ip_helper.ParseIPBlock(jc.Get("ipAddr").String()); err == nil {
+				paths = append(paths, managementPath{name: "everoute controller", block: block})
+			}
+		}
+	}
+
+	if !plan.ProtectedCIDRs.IsNull() && !plan.ProtectedCIDRs.IsUnknown() {
+		for _, e := range plan.ProtectedCIDRs.Elements() {
+			block, ok := e.(ip_helper.IPBlock)
+			if !ok || block.IsNull() || block.IsUnknown() {
+				continue
+			}
+			if prefix, err := ip_helper.ParseIPBlock(block.ValueString()); err == nil {
+				paths = append(paths, managementPath{name: "protected cidr", block: prefix})
+			}
+		}
+	}
+	return paths, diags
+}
@@ -5,17 +5,23 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
 	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
 	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
 	"github.com/tidwall/gjson"
 )
 
@@ -23,6 +29,7 @@ import (
 var _ resource.Resource = &Resource{}
 var _ resource.ResourceWithImportState = &Resource{}
 var _ resource.ResourceWithValidateConfig = &Resource{}
+var _ resource.ResourceWithModifyPlan = &Resource{}
 
 func NewResource() resource.Resource {
 	return &Resource{}
@@ -35,12 +42,16 @@ type Resource struct {
 
 // GlobalSecurityPolicyResourceModel describes the resource data model.
 type GlobalSecurityPolicyResourceModel struct {
-	Id            types.String             `tfsdk:"id"`
-	ServiceId     types.String             `tfsdk:"service_id"`
-	Enable        types.Bool               `tfsdk:"enable"`
-	DefaultAction types.String             `tfsdk:"default_action"`
-	Ingress       []NetworkPolicyRuleModel `tfsdk:"ingress"`
-	Egress        []NetworkPolicyRuleModel `tfsdk:"egress"`
+	Id                     types.String                          `tfsdk:"id"`
+	ServiceId              types.String                          `tfsdk:"service_id"`
+	Enable                 types.Bool                            `tfsdk:"enable"`
+	DefaultAction          types.String                          `tfsdk:"default_action"`
+	Ingress                []network_policy_helper.PeerRuleModel `tfsdk:"ingress"`
+	Egress                 []network_policy_helper.PeerRuleModel `tfsdk:"egress"`
+	StrictRuleAnalysis     types.Bool                            `tfsdk:"strict_rule_analysis"`
+	OnDestroy              types.String                          `tfsdk:"on_destroy"`
+	ProtectedCIDRs         types.List                            `tfsdk:"protected_cidrs"`
+	AcknowledgeLockoutRisk types.Bool                            `tfsdk:"acknowledge_lockout_risk"`
 }
 
 func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -50,7 +61,11 @@ func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, r
 func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		// This description is used by the documentation generator and the language server.
-		MarkdownDescription: "everoute service global security policy configuration",
+		MarkdownDescription: "everoute service global security policy configuration. " +
+			"Everoute has no monitor mode for the global whitelist, changes of default_action are enforced at once, " +
+			"stage a rollout with everoute_security_policy's policy_mode MONITOR first. " +
+			"Import by id or name of the everoute service, all rules are read then; " +
+			"config generated by terraform plan -generate-config-out keeps null attributes, they can be removed",
 
 		Attributes: map[string]schema.Attribute{
 			"service_id": schema.StringAttribute{
@@ -61,7 +76,7 @@ func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp
 				},
 			},
 			"enable": schema.BoolAttribute{
-				MarkdownDescription: "if global security policy is enabled",
+				MarkdownDescription: "if global security policy is enabled, rules of a disabled policy are staged and take effect once enabled",
 				Required:            true,
 			},
 			"default_action": schema.StringAttribute{
@@ -71,15 +86,50 @@ func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp
 					stringvalidator.OneOf("ALLOW", "DROP"),
 				},
 			},
-			"ingress": schema.ListNestedAttribute{
-				MarkdownDescription: "global security policy's ingress configuration",
-				Required:            true,
-				NestedObject:        networkPolicyRuleSchema(),
+			"ingress": schema.SetNestedAttribute{
+				MarkdownDescription: "global security policy's ingress rules, order insensitive, " +
+					"leave it unset to keep rules managed outside, like by everoute_global_security_policy_rule",
+				Optional:     true,
+				NestedObject: network_policy_helper.PeerRuleSchema(),
 			},
-			"egress": schema.ListNestedAttribute{
-				MarkdownDescription: "global security policy's egress configuration",
-				Required:            true,
-				NestedObject:        networkPolicyRuleSchema(),
+			"egress": schema.SetNestedAttribute{
+				MarkdownDescription: "global security policy's egress rules, order insensitive, " +
+					"leave it unset to keep rules managed outside, like by everoute_global_security_policy_rule",
+				Optional:     true,
+				NestedObject: network_policy_helper.PeerRuleSchema(),
+			},
+			"strict_rule_analysis": schema.BoolAttribute{
+				MarkdownDescription: "if rules duplicating or fully covered by another rule are reported as errors instead of warnings",
+				Default:             booldefault.StaticBool(false),
+				Optional:            true,
+				Computed:            true,
+			},
+			"protected_cidrs": schema.ListAttribute{
+				MarkdownDescription: "ip blocks which must stay reachable on all tcp ports when default_action is DROP, " +
+					"checked at plan time together with the cloudtower server on the port the provider connects to and the service's controllers",
+				ElementType: ip_helper.IPBlockType{},
+				Optional:    true,
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(ip_helper.GetIPBlockValidator()),
+				},
+			},
+			"acknowledge_lockout_risk": schema.BoolAttribute{
+				MarkdownDescription: "if default_action DROP is allowed to block management paths not allowed by ip block ingress or egress rules, " +
+					"reported as warnings instead of errors",
+				Default:  booldefault.StaticBool(false),
+				Optional: true,
+				Computed: true,
+			},
+			"on_destroy": schema.StringAttribute{
+				MarkdownDescription: "what to do with the service's global security policy on destroy, valid value: " +
+					"restore (the policy before created or imported), reset (disabled whitelist with ALLOW), keep (leave as is), " +
+					"rules of unset ingress or egress are left as is",
+				Default:  stringdefault.StaticString(onDestroyRestore),
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(onDestroyRestore, onDestroyReset, onDestroyKeep),
+				},
 			},
 			"id": schema.StringAttribute{
 				Computed:            true,
@@ -126,22 +176,14 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 
 	// precheck everoute service existed
 	serviceId := data.ServiceId.ValueString()
-	result, _, err := r.client.DgqlApi.Raw(ctx, getGlobalWhitelistDocument, "getEverouteClusters", map[string]interface{}{
-		"where": map[string]interface{}{
-			"id": serviceId,
-		},
-	}, nil)
-
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to create global security policy",
-			fmt.Sprintf("Failed to get everoute service: %s", err),
-		)
+	unlock := r.client.LockService(serviceId)
+	defer unlock()
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-
-	jService := result.Get("everouteClusters.0")
-	if jService.Type == gjson.Null {
+	if jService == nil {
 		resp.Diagnostics.AddError(
 			"Failed to create global security policy",
 			fmt.Sprintf("Everoute service %s not found", serviceId),
@@ -149,8 +191,14 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		return
 	}
 
+	// snapshot global whitelist to restore on destroy
+	resp.Diagnostics.Append(saveOriginalWhitelist(ctx, resp.Private, jService)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	// create global whitelist
-	updateInput, diags := buildUpdateInput(data)
+	updateInput, diags := buildUpdateInput(ctx, r.client, data, jService)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -179,18 +227,19 @@ func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp
 		)
 		return
 	}
-	result, _, err = r.client.DgqlApi.Raw(ctx, getGlobalWhitelistDocument, "", map[string]interface{}{}, nil)
-
-	if err != nil {
+	jService, diags = getGlobalWhitelistGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jService == nil {
 		resp.Diagnostics.AddError(
-			"Failed to get everoute service global security policy",
-			fmt.Sprintf("Failed to get everoute service global security policy: %s", err),
+			"Failed to create global security policy",
+			fmt.Sprintf("Everoute service %s not found after updated", serviceId),
 		)
 		return
 	}
-
-	jService = result.Get("everouteClusters.0")
-	readGqlResultToState(&jService, data)
+	resp.Diagnostics.Append(readGqlResultToState(ctx, r.client, jService, data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -203,19 +252,22 @@ func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *res
 
 	// Read Terraform prior state data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
-
-	result, _, err := r.client.DgqlApi.Raw(ctx, getGlobalWhitelistDocument, "", map[string]interface{}{}, nil)
-
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to get everoute service global security policy",
-			fmt.Sprintf("Failed to get everoute service global security policy: %s", err),
-		)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	jService := result.Get("everouteClusters.0")
-	readGqlResultToState(&jService, data)
+	// only id is known after imported
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, data.Id.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// everoute service deleted outside terraform
+	if jService == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, r.client, jService, data)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -231,7 +283,23 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
-	updateInput, diags := buildUpdateInput(plan)
+	serviceId := plan.ServiceId.ValueString()
+	unlock := r.client.LockService(serviceId)
+	defer unlock()
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jService == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update global security policy",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return
+	}
+
+	updateInput, diags := buildUpdateInput(ctx, r.client, plan, jService)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -263,18 +331,19 @@ func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp
 		return
 	}
 
-	result, _, err := r.client.DgqlApi.Raw(ctx, getGlobalWhitelistDocument, "", map[string]interface{}{}, nil)
-
-	if err != nil {
+	jService, diags = getGlobalWhitelistGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jService == nil {
 		resp.Diagnostics.AddError(
-			"Failed to get everoute service global security policy",
-			fmt.Sprintf("Failed to get everoute service global security policy: %s", err),
+			"Failed to update global security policy",
+			fmt.Sprintf("Everoute service %s not found after updated", serviceId),
 		)
 		return
 	}
-
-	jService := result.Get("everouteClusters.0")
-	readGqlResultToState(&jService, plan)
+	resp.Diagnostics.Append(readGqlResultToState(ctx, r.client, jService, plan)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -288,19 +357,53 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 
 	// Read Terraform prior state data into the model
 	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	unlock := r.client.LockService(data.ServiceId.ValueString())
+	defer unlock()
+
+	var input map[string]interface{}
+	switch data.OnDestroy.ValueString() {
+	case onDestroyKeep:
+		return
+	case onDestroyReset:
+		input = resetInput()
+	default:
+		original, diags := loadOriginalWhitelist(ctx, req.Private)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if original == nil {
+			resp.Diagnostics.AddWarning(
+				"No global security policy to restore",
+				"global security policy was not snapshotted when created or imported, reset instead",
+			)
+			original = resetInput()
+		}
+		input = original
+	}
+	// rules of unset directions are managed by rule resources, so they are kept as in current service
+	if data.Ingress == nil || data.Egress == nil {
+		jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, data.ServiceId.ValueString())
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		// nothing to restore of a service deleted outside terraform
+		if jService == nil {
+			return
+		}
+		keepUnsetRules(input, data, jService)
+	}
 
 	_, headers, err := r.client.DgqlApi.Raw(ctx, updateGlobalWhiteListDocument, "updateEverouteClusterGlobalAction", map[string]interface{}{
 		"where": map[string]interface{}{
 			"id": data.ServiceId.ValueString(),
 		},
-		"data": map[string]interface{}{
-			"global_default_action": "ALLOW",
-			"global_whitelist": map[string]interface{}{
-				"enable":  false,
-				"egress":  []interface{}{},
-				"ingress": []interface{}{},
-			},
-		},
+		"data": input,
 	}, nil)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -323,8 +426,71 @@ func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp
 	}
 }
 
+// ImportState accepts id or name of the everoute service the global security policy belongs to.
 func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	serviceId, diags := findServiceId(ctx, r.client, req.ID)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, serviceId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jService == nil {
+		resp.Diagnostics.AddError(
+			"Failed to import global security policy",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return
+	}
+	// snapshot global whitelist to restore on destroy
+	resp.Diagnostics.Append(saveOriginalWhitelist(ctx, resp.Private, jService)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), serviceId)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service_id"), serviceId)...)
+}
+
+// findServiceId returns id of the everoute service of id or unique name idOrName.
+func findServiceId(ctx context.Context, client *everoute.Client, idOrName string) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getEverouteClusterIdsDocument, "getEverouteClusterIds", map[string]interface{}{
+		"where": map[string]interface{}{
+			"OR": []map[string]interface{}{
+				{"id": idOrName},
+				{"name": idOrName},
+			},
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to import global security policy",
+			fmt.Sprintf("Failed to get everoute service: %s", err),
+		)
+		return "", diags
+	}
+	jServices := result.Get("everouteClusters").Array()
+	for _, jService := range jServices {
+		// id takes precedence over a service named like another service's id
+		if jService.Get("id").String() == idOrName {
+			return idOrName, diags
+		}
+	}
+	switch len(jServices) {
+	case 0:
+		diags.AddError(
+			"Failed to import global security policy",
+			fmt.Sprintf("Everoute service %s not found", idOrName),
+		)
+	case 1:
+		return jServices[0].Get("id").String(), diags
+	default:
+		diags.AddError(
+			"Failed to import global security policy",
+			fmt.Sprintf("Several everoute services are named %s, import by id instead", idOrName),
+		)
+	}
+	return "", diags
 }
 
 func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
@@ -333,108 +499,155 @@ func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConf
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	enabled := data.Enable.ValueBool()
-	if !enabled {
-		if len(data.Egress) != 0 {
-			resp.Diagnostics.AddError(
-				"Invalid egress",
-				"egress is not allowed when global security policy is disabled",
-			)
-		}
-		if len(data.Ingress) != 0 {
-			resp.Diagnostics.AddError(
-				"Invalid ingress",
-				"ingress is not allowed when global security policy is disabled",
-			)
-		}
-	} else if len(data.Egress)+len(data.Ingress) == 0 {
+	// rules of a disabled policy are staged
+	if data.Enable.ValueBool() && data.Egress != nil && data.Ingress != nil && len(data.Egress)+len(data.Ingress) == 0 {
 		resp.Diagnostics.AddError(
 			"Invalid egress and ingress",
 			"egress or ingress cannot be both empty when global security policy is enabled",
 		)
 	}
+	resp.Diagnostics.Append(network_policy_helper.ValidatePeerRuleNames(data.Ingress, "ingress")...)
+	resp.Diagnostics.Append(network_policy_helper.ValidatePeerRuleNames(data.Egress, "egress")...)
 }
 
-func readGqlResultToState(input *gjson.Result, state *GlobalSecurityPolicyResourceModel) diag.Diagnostics {
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// resource is being destroyed
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan *GlobalSecurityPolicyResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	strict := plan.StrictRuleAnalysis.ValueBool()
+	for _, direction := range []string{"ingress", "egress"} {
+		// rules are decoded element by element, so each rule is located by its own set element in diagnostics
+		var elements types.Set
+		resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root(direction), &elements)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		rules := make([]network_policy_helper.PeerRuleModel, len(elements.Elements()))
+		for i, element := range elements.Elements() {
+			object, ok := element.(types.Object)
+			if !ok {
+				continue
+			}
+			resp.Diagnostics.Append(object.As(ctx, &rules[i], basetypes.ObjectAsOptions{})...)
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		for _, shadow := range network_policy_helper.AnalyzePeerRules(ctx, rules) {
+			rulePath := path.Root(direction).AtSetValue(elements.Elements()[shadow.Index])
+			if strict {
+				resp.Diagnostics.AddAttributeError(rulePath, "Redundant global security policy rule", shadow.Message(direction))
+			} else {
+				resp.Diagnostics.AddAttributeWarning(rulePath, "Redundant global security policy rule", shadow.Message(direction))
+			}
+		}
+	}
+	changed, diags := lockoutInputsChanged(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if changed {
+		resp.Diagnostics.Append(r.checkLockout(ctx, plan)...)
+	}
+}
+
+func readGqlResultToState(ctx context.Context, client *everoute.Client, input *gjson.Result, state *GlobalSecurityPolicyResourceModel) diag.Diagnostics {
 	var diags diag.Diagnostics
 	state.Id = types.StringValue(input.Get("id").String())
 	state.ServiceId = state.Id
+	// terraform only settings, default after imported
+	if state.StrictRuleAnalysis.IsNull() {
+		state.StrictRuleAnalysis = types.BoolValue(false)
+	}
+	if state.OnDestroy.IsNull() {
+		state.OnDestroy = types.StringValue(onDestroyRestore)
+	}
+	if state.AcknowledgeLockoutRisk.IsNull() {
+		state.AcknowledgeLockoutRisk = types.BoolValue(false)
+	}
+	// unset rules are managed outside, all rules are read after imported
+	imported := state.Enable.IsNull()
 	state.Enable = types.BoolValue(input.Get("global_whitelist.enable").Bool())
 	state.DefaultAction = types.StringValue(input.Get("global_default_action").String())
 	jingress := input.Get("global_whitelist.ingress")
 	jegress := input.Get("global_whitelist.egress")
-	state.Ingress = readGqlResultToNetworkPolicyRule(&jingress)
-	state.Egress = readGqlResultToNetworkPolicyRule(&jegress)
+	if state.Ingress != nil || imported {
+		ingress, d := network_policy_helper.CollapseVmPeerRules(ctx, client.Api, network_policy_helper.ReadGqlResultToPeerRules(&jingress), state.Ingress)
+		diags.Append(d...)
+		state.Ingress = uniquePeerRules(network_policy_helper.AlignPeerRules(ctx, ingress, state.Ingress))
+	}
+	if state.Egress != nil || imported {
+		egress, d := network_policy_helper.CollapseVmPeerRules(ctx, client.Api, network_policy_helper.ReadGqlResultToPeerRules(&jegress), state.Egress)
+		diags.Append(d...)
+		state.Egress = uniquePeerRules(network_policy_helper.AlignPeerRules(ctx, egress, state.Egress))
+	}
 	return diags
 }
 
-func buildUpdateInput(state *GlobalSecurityPolicyResourceModel) (map[string]interface{}, diag.Diagnostics) {
+// getGlobalWhitelistGqlResult returns nil result without error when everoute service not found.
+func getGlobalWhitelistGqlResult(ctx context.Context, client *everoute.Client, serviceId string) (*gjson.Result, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	ingress := make([]map[string]interface{}, 0)
-	egress := make([]map[string]interface{}, 0)
-	enabled := state.Enable.ValueBool()
-	if enabled {
-		for _, rule := range state.Ingress {
-			ports := make([]map[string]interface{}, 0)
-			if rule.TCPEnabled.ValueBool() {
-				tcpport := make(map[string]interface{})
-				tcpport["protocol"] = "TCP"
-				tcpport["port"] = rule.TCPPorts.ValueString()
-				ports = append(ports, tcpport)
-			}
-			if rule.UDPEnabled.ValueBool() {
-				udpport := make(map[string]interface{})
-				udpport["protocol"] = "UDP"
-				udpport["port"] = rule.UDPPorts.ValueString()
-				ports = append(ports, udpport)
-			}
-			if rule.ICMPEnabled.ValueBool() {
-				icmpport := make(map[string]interface{})
-				icmpport["protocol"] = "ICMP"
-				ports = append(ports, icmpport)
+	result, _, err := client.DgqlApi.Raw(ctx, getGlobalWhitelistDocument, "getEverouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to get everoute service global security policy",
+			fmt.Sprintf("Failed to get everoute service global security policy: %s", err),
+		)
+		return nil, diags
+	}
+	jService := result.Get("everouteClusters.0")
+	if jService.Type == gjson.Null {
+		return nil, diags
+	}
+	return &jService, diags
+}
+
+// uniquePeerRules drops repeated rules, which may be created outside terraform but cannot be held by a set.
+func uniquePeerRules(rules []network_policy_helper.PeerRuleModel) []network_policy_helper.PeerRuleModel {
+	unique := make([]network_policy_helper.PeerRuleModel, 0, len(rules))
+	for _, rule := range rules {
+		repeated := false
+		for _, u := range unique {
+			if rule.Equal(&u) {
+				repeated = true
+				break
 			}
-			eips := make([]string, 0)
-			diags = rule.ExceptIPBlock.ElementsAs(context.Background(), &eips, false)
-			ingress = append(ingress, map[string]interface{}{
-				"type":            "IP_BLOCK",
-				"ip_block":        rule.IPBlock.ValueString(),
-				"ports":           ports,
-				"except_ip_block": eips,
-			})
 		}
-		for _, rule := range state.Egress {
-			ports := make([]map[string]interface{}, 0)
-			if rule.TCPEnabled.ValueBool() {
-				tcpport := make(map[string]interface{})
-				tcpport["protocol"] = "TCP"
-				tcpport["port"] = rule.TCPPorts.ValueString()
-				ports = append(ports, tcpport)
-			}
-			if rule.UDPEnabled.ValueBool() {
-				udpport := make(map[string]interface{})
-				udpport["protocol"] = "UDP"
-				udpport["port"] = rule.UDPPorts.ValueString()
-				ports = append(ports, udpport)
-			}
-			if rule.ICMPEnabled.ValueBool() {
-				icmpport := make(map[string]interface{})
-				icmpport["protocol"] = "ICMP"
-				ports = append(ports, icmpport)
-			}
-			eips := make([]string, 0)
-			diags = rule.ExceptIPBlock.ElementsAs(context.Background(), &eips, false)
-			egress = append(egress, map[string]interface{}{
-				"type":            "IP_BLOCK",
-				"ip_block":        rule.IPBlock.ValueString(),
-				"ports":           ports,
-				"except_ip_block": eips,
-			})
+		if !repeated {
+			unique = append(unique, rule)
 		}
 	}
+	return unique
+}
+
+// buildUpdateInput builds global security policy of state, unset rules are kept as in current service.
+func buildUpdateInput(ctx context.Context, client *everoute.Client, state *GlobalSecurityPolicyResourceModel, current *gjson.Result) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	// rules are sent even if disabled, so they are staged until enabled
+	ingress, d := network_policy_helper.BuildPeerRulesInput(ctx, client.Api, state.Ingress)
+	diags.Append(d...)
+	egress, d := network_policy_helper.BuildPeerRulesInput(ctx, client.Api, state.Egress)
+	diags.Append(d...)
+	if state.Ingress == nil {
+		jingress := current.Get("global_whitelist.ingress")
+		ingress = network_policy_helper.ReadGqlResultToPeerRuleInputs(&jingress)
+	}
+	if state.Egress == nil {
+		jegress := current.Get("global_whitelist.egress")
+		egress = network_policy_helper.ReadGqlResultToPeerRuleInputs(&jegress)
+	}
 	return map[string]interface{}{
 		"global_default_action": state.DefaultAction.ValueString(),
 		"global_whitelist": map[string]interface{}{
-			"enable":  enabled,
+			"enable":  state.Enable.ValueBool(),
 			"egress":  egress,
 			"ingress": ingress,
 		},
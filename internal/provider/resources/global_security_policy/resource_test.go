@@ -0,0 +1,390 @@
+package global_security_policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute/fake_cloudtower"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+const whitelistResult = `{
+	"id": "service-1",
+	"global_default_action": "DROP",
+	"global_whitelist": {
+		"enable": %s,
+		"ingress": [
+			{"type": "IP_BLOCK", "ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}]}
+		],
+		"egress": []
+	}
+}`
+
+func TestReadGqlResultToStateAfterImport(t *testing.T) {
+	for _, enable := range []string{"true", "false"} {
+		t.Run("enable="+enable, func(t *testing.T) {
+			input := gjson.Parse(fmt.Sprintf(whitelistResult, enable))
+			// only id is known after imported
+			state := &GlobalSecurityPolicyResourceModel{Id: types.StringValue("service-1")}
+			if diags := readGqlResultToState(context.Background(), &everoute.Client{}, &input, state); diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if state.Enable.ValueBool() != (enable == "true") {
+				t.Errorf("enable = %v, want %s", state.Enable, enable)
+			}
+			if len(state.Ingress) != 1 || state.Ingress[0].IPBlock.ValueString() != "10.0.0.1" {
+				t.Errorf("ingress = %v, want the rule of 10.0.0.1", state.Ingress)
+			}
+			if state.Egress == nil || len(state.Egress) != 0 {
+				t.Errorf("egress = %v, want managed and empty", state.Egress)
+			}
+		})
+	}
+}
+
+func TestReadGqlResultToStateKeepsUnsetRules(t *testing.T) {
+	input := gjson.Parse(fmt.Sprintf(whitelistResult, "true"))
+	state := &GlobalSecurityPolicyResourceModel{
+		Id:     types.StringValue("service-1"),
+		Enable: types.BoolValue(true),
+	}
+	if diags := readGqlResultToState(context.Background(), &everoute.Client{}, &input, state); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if state.Ingress != nil || state.Egress != nil {
+		t.Errorf("rules managed outside are read: ingress %v, egress %v", state.Ingress, state.Egress)
+	}
+}
+
+func TestDeleteKeepsUnsetRules(t *testing.T) {
+	for _, onDestroy := range []string{onDestroyRestore, onDestroyReset} {
+		t.Run(onDestroy, func(t *testing.T) {
+			server := fake_cloudtower.NewServer(t)
+			server.Handle("getEverouteClusters", func(variables gjson.Result) (interface{}, error) {
+				return gjson.Parse(`{"everouteClusters": [` + fmt.Sprintf(whitelistResult, "true") + `]}`).Value(), nil
+			})
+			var update gjson.Result
+			server.Handle("updateEverouteClusterGlobalAction", func(variables gjson.Result) (interface{}, error) {
+				update = variables
+				return map[string]interface{}{"updateEverouteCluster": map[string]interface{}{"id": "service-1"}}, nil
+			})
+			r := &Resource{client: server.Client(t)}
+			schemaResp := &resource.SchemaResponse{}
+			r.Schema(context.Background(), resource.SchemaRequest{}, schemaResp)
+			state := tfsdk.State{Schema: schemaResp.Schema}
+			// ingress and egress are left to rule resources
+			diags := state.Set(context.Background(), &GlobalSecurityPolicyResourceModel{
+				Id:                     types.StringValue("service-1"),
+				ServiceId:              types.StringValue("service-1"),
+				Enable:                 types.BoolValue(true),
+				DefaultAction:          types.StringValue("DROP"),
+				StrictRuleAnalysis:     types.BoolValue(false),
+				OnDestroy:              types.StringValue(onDestroy),
+				ProtectedCIDRs:         types.ListNull(ip_helper.IPBlockType{}),
+				AcknowledgeLockoutRisk: types.BoolValue(false),
+			})
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			resp := &resource.DeleteResponse{State: state}
+			r.Delete(context.Background(), resource.DeleteRequest{State: state}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+			}
+			if action := update.Get("data.global_default_action").String(); action != "ALLOW" {
+				t.Errorf("default action = %s, want reset to ALLOW", action)
+			}
+			if ingress := update.Get("data.global_whitelist.ingress").Array(); len(ingress) != 1 || ingress[0].Get("ip_block").String() != "10.0.0.1" {
+				t.Errorf("ingress = %v, want the rule of rule resources kept", ingress)
+			}
+		})
+	}
+}
+
+func TestUniquePeerRules(t *testing.T) {
+	input := gjson.Parse(`[
+		{"type": "IP_BLOCK", "ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}]},
+		{"type": "IP_BLOCK", "ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}]},
+		{"type": "IP_BLOCK", "ip_block": "10.0.0.2", "except_ip_block": [], "services": [], "ports": [{"protocol": "TCP", "port": "22"}]}
+	]`)
+	rules := uniquePeerRules(network_policy_helper.ReadGqlResultToPeerRules(&input))
+	if len(rules) != 2 {
+		t.Errorf("got %d rules, want repeated rule dropped", len(rules))
+	}
+}
+
+func TestLockoutInputsChanged(t *testing.T) {
+	r := &Resource{}
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, schemaResp)
+	s := schemaResp.Schema
+	value := func(action string, note string) tftypes.Value {
+		plan := tfsdk.Plan{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+		data := &GlobalSecurityPolicyResourceModel{
+			Id:                     types.StringValue("service-1"),
+			ServiceId:              types.StringValue("service-1"),
+			Enable:                 types.BoolValue(true),
+			DefaultAction:          types.StringValue(action),
+			StrictRuleAnalysis:     types.BoolValue(false),
+			OnDestroy:              types.StringValue(note),
+			ProtectedCIDRs:         types.ListNull(ip_helper.IPBlockType{}),
+			AcknowledgeLockoutRisk: types.BoolValue(false),
+		}
+		if diags := plan.Set(context.Background(), data); diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+		return plan.Raw
+	}
+	cases := []struct {
+		name  string
+		prior tftypes.Value
+		want  bool
+	}{
+		{"created", tftypes.NewValue(s.Type().TerraformType(context.Background()), nil), true},
+		{"default action changed", value("ALLOW", onDestroyKeep), true},
+		{"other attribute changed", value("DROP", onDestroyReset), false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := resource.ModifyPlanRequest{
+				Plan:  tfsdk.Plan{Schema: s, Raw: value("DROP", onDestroyKeep)},
+				State: tfsdk.State{Schema: s, Raw: c.prior},
+			}
+			changed, diags := lockoutInputsChanged(context.Background(), req)
+			if diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if changed != c.want {
+				t.Errorf("changed = %v, want %v", changed, c.want)
+			}
+		})
+	}
+}
+
+func TestReadGqlResultToStateOfDisabledWhitelist(t *testing.T) {
+	input := gjson.Parse(`{
+		"id": "service-1",
+		"global_default_action": "ALLOW",
+		"global_whitelist": {"enable": false, "ingress": [], "egress": []}
+	}`)
+	jrules := gjson.Parse(`[{"type": "IP_BLOCK", "ip_block": "10.0.0.1", "except_ip_block": [], "services": [], "ports": []}]`)
+	state := &GlobalSecurityPolicyResourceModel{
+		Id:      types.StringValue("service-1"),
+		Enable:  types.BoolValue(false),
+		Ingress: network_policy_helper.ReadGqlResultToPeerRules(&jrules),
+	}
+	if diags := readGqlResultToState(context.Background(), &everoute.Client{}, &input, state); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	// rules cleared outside are drift, even if the whitelist is disabled
+	if len(state.Ingress) != 0 {
+		t.Errorf("ingress = %v, want rules read as returned", state.Ingress)
+	}
+}
+
+// fakeServices holds everoute services in the order they are listed.
+type fakeServices struct {
+	mu       sync.Mutex
+	services []map[string]interface{}
+}
+
+func newFakeServices(t *testing.T, services ...map[string]interface{}) (*Resource, *fakeServices) {
+	fake := &fakeServices{services: services}
+	server := fake_cloudtower.NewServer(t)
+	server.Handle("getEverouteClusters", func(variables gjson.Result) (interface{}, error) {
+		id := variables.Get("where.id")
+		return map[string]interface{}{"everouteClusters": fake.list(func(service map[string]interface{}) bool {
+			return !id.Exists() || service["id"] == id.String()
+		})}, nil
+	})
+	server.Handle("getEverouteClusterIds", func(variables gjson.Result) (interface{}, error) {
+		return map[string]interface{}{"everouteClusters": fake.list(func(service map[string]interface{}) bool {
+			for _, or := range variables.Get("where.OR").Array() {
+				if service["id"] == or.Get("id").String() || service["name"] == or.Get("name").String() {
+					return true
+				}
+			}
+			return false
+		})}, nil
+	})
+	server.Handle("updateEverouteClusterGlobalAction", func(variables gjson.Result) (interface{}, error) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		id := variables.Get("where.id").String()
+		for _, service := range fake.services {
+			if service["id"] == id {
+				service["global_default_action"] = variables.Get("data.global_default_action").Value()
+				service["global_whitelist"] = variables.Get("data.global_whitelist").Value()
+				return map[string]interface{}{"updateEverouteCluster": map[string]interface{}{"id": id}}, nil
+			}
+		}
+		return nil, fmt.Errorf("everoute service %s not found", id)
+	})
+	return &Resource{client: server.Client(t)}, fake
+}
+
+func (f *fakeServices) list(match func(map[string]interface{}) bool) []interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	services := []interface{}{}
+	for _, service := range f.services {
+		if match(service) {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+func (f *fakeServices) get(t *testing.T, id string) gjson.Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, service := range f.services {
+		if service["id"] == id {
+			data, err := json.Marshal(service)
+			if err != nil {
+				t.Fatalf("failed to marshal service: %s", err)
+			}
+			return gjson.ParseBytes(data)
+		}
+	}
+	t.Fatalf("everoute service %s not found", id)
+	return gjson.Result{}
+}
+
+func testService(id string, name string, action string, ipBlocks ...string) map[string]interface{} {
+	ingress := []interface{}{}
+	for _, ipBlock := range ipBlocks {
+		ingress = append(ingress, map[string]interface{}{
+			"type":            "IP_BLOCK",
+			"ip_block":        ipBlock,
+			"except_ip_block": []interface{}{},
+			"services":        []interface{}{},
+			"ports":           []interface{}{map[string]interface{}{"protocol": "TCP", "port": "22"}},
+		})
+	}
+	return map[string]interface{}{
+		"id":                    id,
+		"name":                  name,
+		"global_default_action": action,
+		"global_whitelist": map[string]interface{}{
+			"enable":  len(ingress) > 0,
+			"ingress": ingress,
+			"egress":  []interface{}{},
+		},
+	}
+}
+
+func testState(t *testing.T, r *Resource, data *GlobalSecurityPolicyResourceModel) tfsdk.State {
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, schemaResp)
+	state := tfsdk.State{Schema: schemaResp.Schema}
+	if diags := state.Set(context.Background(), data); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return state
+}
+
+func testModel(t *testing.T, serviceId string, action string, ipBlocks ...string) *GlobalSecurityPolicyResourceModel {
+	jservice := gjson.Parse(mustMarshal(t, testService(serviceId, "", action, ipBlocks...)))
+	jingress := jservice.Get("global_whitelist.ingress")
+	return &GlobalSecurityPolicyResourceModel{
+		Id:                     types.StringValue(serviceId),
+		ServiceId:              types.StringValue(serviceId),
+		Enable:                 types.BoolValue(len(ipBlocks) > 0),
+		DefaultAction:          types.StringValue(action),
+		Ingress:                network_policy_helper.ReadGqlResultToPeerRules(&jingress),
+		Egress:                 []network_policy_helper.PeerRuleModel{},
+		StrictRuleAnalysis:     types.BoolValue(false),
+		OnDestroy:              types.StringValue(onDestroyRestore),
+		ProtectedCIDRs:         types.ListNull(ip_helper.IPBlockType{}),
+		AcknowledgeLockoutRisk: types.BoolValue(false),
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	return string(data)
+}
+
+func TestServicesAreManagedIndependently(t *testing.T) {
+	// service-a is listed first, so a read or update not scoped to its service gets service-a
+	r, fake := newFakeServices(t,
+		testService("service-a", "a", "DROP", "10.0.0.1"),
+		testService("service-b", "b", "ALLOW"),
+	)
+	serviceA := fake.get(t, "service-a").Raw
+
+	state := testState(t, r, testModel(t, "service-b", "DROP", "10.0.0.9"))
+	readResp := &resource.ReadResponse{State: state}
+	r.Read(context.Background(), resource.ReadRequest{State: state}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", readResp.Diagnostics)
+	}
+	var read *GlobalSecurityPolicyResourceModel
+	if diags := readResp.State.Get(context.Background(), &read); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if read.DefaultAction.ValueString() != "ALLOW" || read.Enable.ValueBool() || len(read.Ingress) != 0 {
+		t.Errorf("read %s %v with %d ingress rules, want the disabled ALLOW policy of service-b",
+			read.DefaultAction, read.Enable, len(read.Ingress))
+	}
+
+	plan := testState(t, r, testModel(t, "service-b", "DROP", "10.0.0.2"))
+	updateResp := &resource.UpdateResponse{State: state}
+	r.Update(context.Background(), resource.UpdateRequest{Plan: tfsdk.Plan(plan), State: state}, updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", updateResp.Diagnostics)
+	}
+	serviceB := fake.get(t, "service-b")
+	if serviceB.Get("global_default_action").String() != "DROP" || serviceB.Get("global_whitelist.ingress.0.ip_block").String() != "10.0.0.2" {
+		t.Errorf("service-b = %s, want the updated policy", serviceB.Raw)
+	}
+	if got := fake.get(t, "service-a").Raw; got != serviceA {
+		t.Errorf("service-a = %s, want it untouched as %s", got, serviceA)
+	}
+}
+
+func TestFindServiceId(t *testing.T) {
+	r, _ := newFakeServices(t,
+		testService("service-a", "a", "ALLOW"),
+		testService("service-b", "b", "ALLOW"),
+		testService("service-c", "shared", "ALLOW"),
+		testService("service-d", "shared", "ALLOW"),
+		testService("service-e", "service-a", "ALLOW"),
+	)
+	cases := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "service-b", want: "service-b"},
+		{id: "b", want: "service-b"},
+		{id: "service-a", want: "service-a"},
+		{id: "shared", wantErr: true},
+		{id: "missing", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.id, func(t *testing.T) {
+			got, diags := findServiceId(context.Background(), r.client, c.id)
+			if diags.HasError() != c.wantErr {
+				t.Fatalf("diagnostics = %v, want error %v", diags, c.wantErr)
+			}
+			if got != c.want {
+				t.Errorf("service id = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
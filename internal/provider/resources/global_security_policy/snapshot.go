@@ -0,0 +1,93 @@
+package global_security_policy
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	onDestroyRestore = "restore"
+	onDestroyReset   = "reset"
+	onDestroyKeep    = "keep"
+)
+
+// privateKeyOriginalWhitelist keeps the service's global security policy before terraform managed it.
+const privateKeyOriginalWhitelist = "original_whitelist"
+
+// privateState is the subset of private state methods of create, import and delete requests and responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// resetInput disables the global whitelist and allows all traffic.
+func resetInput() map[string]interface{} {
+	return map[string]interface{}{
+		"global_default_action": "ALLOW",
+		"global_whitelist": map[string]interface{}{
+			"enable":  false,
+			"egress":  []interface{}{},
+			"ingress": []interface{}{},
+		},
+	}
+}
+
+// saveOriginalWhitelist snapshots service's global security policy as update input into private state.
+func saveOriginalWhitelist(ctx context.Context, private privateState, jService *gjson.Result) diag.Diagnostics {
+	var diags diag.Diagnostics
+	snapshot, err := json.Marshal(snapshotInput(jService))
+	if err != nil {
+		diags.AddError("Failed to snapshot global security policy", err.Error())
+		return diags
+	}
+	return private.SetKey(ctx, privateKeyOriginalWhitelist, snapshot)
+}
+
+// loadOriginalWhitelist returns nil input without error if no snapshot is saved,
+// as for resources created by earlier versions.
+func loadOriginalWhitelist(ctx context.Context, private privateState) (map[string]interface{}, diag.Diagnostics) {
+	snapshot, diags := private.GetKey(ctx, privateKeyOriginalWhitelist)
+	if diags.HasError() || len(snapshot) == 0 {
+		return nil, diags
+	}
+	var input map[string]interface{}
+	if err := json.Unmarshal(snapshot, &input); err != nil {
+		diags.AddError("Failed to load global security policy snapshot", err.Error())
+		return nil, diags
+	}
+	return input, diags
+}
+
+func snapshotInput(jService *gjson.Result) map[string]interface{} {
+	jegress := jService.Get("global_whitelist.egress")
+	jingress := jService.Get("global_whitelist.ingress")
+	return map[string]interface{}{
+		"global_default_action": jService.Get("global_default_action").String(),
+		"global_whitelist": map[string]interface{}{
+			"enable":  jService.Get("global_whitelist.enable").Bool(),
+			"egress":  network_policy_helper.ReadGqlResultToPeerRuleInputs(&jegress),
+			"ingress": network_policy_helper.ReadGqlResultToPeerRuleInputs(&jingress),
+		},
+	}
+}
+
+// keepUnsetRules replaces rules of input with current rules of service for directions unset in state,
+// so that only default action and enable are restored or reset for them.
+func keepUnsetRules(input map[string]interface{}, state *GlobalSecurityPolicyResourceModel, jService *gjson.Result) {
+	whitelist, ok := input["global_whitelist"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	if state.Ingress == nil {
+		jingress := jService.Get("global_whitelist.ingress")
+		whitelist["ingress"] = network_policy_helper.ReadGqlResultToPeerRuleInputs(&jingress)
+	}
+	if state.Egress == nil {
+		jegress := jService.Get("global_whitelist.egress")
+		whitelist["egress"] = network_policy_helper.ReadGqlResultToPeerRuleInputs(&jegress)
+	}
+}
@@ -0,0 +1,62 @@
+package global_security_policy_rule
+
+var getGlobalWhitelistDocument = `
+query getEverouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	  global_whitelist {
+		enable
+		egress {
+		  type
+		  ip_block
+		  except_ip_block
+		  services
+		  selector {
+			id
+			key
+			value
+		  }
+		  security_group_id
+		  ports {
+			port
+			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
+		  }
+		}
+		ingress {
+		  type
+		  ip_block
+		  except_ip_block
+		  services
+		  selector {
+			id
+			key
+			value
+		  }
+		  security_group_id
+		  ports {
+			port
+			protocol
+			alg_protocol
+			icmp_type
+			icmp_code
+		  }
+		}
+	  }
+	}
+  }
+`
+
+var updateGlobalWhiteListDocument = `
+mutation updateEverouteClusterGlobalWhitelist(
+	$where: EverouteClusterWhereUniqueInput!
+	$data: EverouteClusterUpdateInput!
+  ) {
+	updateEverouteCluster(where: $where, data: $data) {
+	  id
+	  __typename
+	}
+  }
+`
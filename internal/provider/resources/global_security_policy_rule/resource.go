@@ -0,0 +1,450 @@
+package global_security_policy_rule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	DirectionIngress = "INGRESS"
+	DirectionEgress  = "EGRESS"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Resource{}
+var _ resource.ResourceWithImportState = &Resource{}
+var _ resource.ResourceWithValidateConfig = &Resource{}
+var _ resource.ResourceWithModifyPlan = &Resource{}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+// Resource defines the resource implementation.
+type Resource struct {
+	client *everoute.Client
+}
+
+// GlobalSecurityPolicyRuleResourceModel describes the resource data model.
+type GlobalSecurityPolicyRuleResourceModel struct {
+	Id        types.String                         `tfsdk:"id"`
+	ServiceId types.String                         `tfsdk:"service_id"`
+	Direction types.String                         `tfsdk:"direction"`
+	Rule      *network_policy_helper.PeerRuleModel `tfsdk:"rule"`
+	RuleKey   types.String                         `tfsdk:"rule_key"`
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_global_security_policy_rule"
+}
+
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	rule := network_policy_helper.PeerRuleSchema()
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "one rule of an everoute service's global whitelist, other rules of the whitelist are kept, " +
+			"can be used together with everoute_global_security_policy whose ingress and egress are not configured",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "identifier, formatted as service_id/direction/rule_key",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"direction": schema.StringAttribute{
+				MarkdownDescription: "whitelist direction the rule belongs to, valid value: INGRESS, EGRESS",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(DirectionIngress, DirectionEgress),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rule": schema.SingleNestedAttribute{
+				MarkdownDescription: "whitelist rule",
+				Required:            true,
+				Attributes:          rule.Attributes,
+				Validators:          rule.Validators,
+				PlanModifiers:       rule.PlanModifiers,
+			},
+			"rule_key": schema.StringAttribute{
+				MarkdownDescription: "stable key of the rule, a hash of its normalized peer, protocols, ports and network services, " +
+					"rules allowing the same traffic share the key",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *GlobalSecurityPolicyRuleResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	input, diags := network_policy_helper.BuildPeerRuleInput(ctx, r.client.Api, data.Rule)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	key := network_policy_helper.PeerRuleInputKey(input)
+
+	resp.Diagnostics.Append(r.updateRules(ctx, data.ServiceId.ValueString(), data.Direction.ValueString(),
+		func(rules []map[string]interface{}) ([]map[string]interface{}, diag.Diagnostics) {
+			var diags diag.Diagnostics
+			if findRule(rules, key) >= 0 {
+				diags.AddError(
+					"Failed to create global security policy rule",
+					fmt.Sprintf("An equivalent %s rule already exists, import it with id %s", data.Direction.ValueString(), buildId(data, key)),
+				)
+				return nil, diags
+			}
+			return append(rules, input), diags
+		})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.RuleKey = types.StringValue(key)
+	data.Id = types.StringValue(buildId(data, key))
+	resp.Diagnostics.Append(r.readRule(ctx, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *GlobalSecurityPolicyRuleResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, data.ServiceId.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// everoute service deleted outside terraform
+	if jService == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	jrules := jService.Get(whitelistPath(data.Direction.ValueString()))
+	idx := findRule(network_policy_helper.ReadGqlResultToPeerRuleInputs(&jrules), data.RuleKey.ValueString())
+	// rule removed outside terraform
+	if idx < 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	// the equivalent rule of the same key is kept as configured, only read after imported
+	if data.Rule == nil {
+		data.Rule = &network_policy_helper.ReadGqlResultToPeerRules(&jrules)[idx]
+	}
+	data.Id = types.StringValue(buildId(data, data.RuleKey.ValueString()))
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state *GlobalSecurityPolicyRuleResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	input, diags := network_policy_helper.BuildPeerRuleInput(ctx, r.client.Api, plan.Rule)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	key := network_policy_helper.PeerRuleInputKey(input)
+	priorKey := state.RuleKey.ValueString()
+
+	resp.Diagnostics.Append(r.updateRules(ctx, plan.ServiceId.ValueString(), plan.Direction.ValueString(),
+		func(rules []map[string]interface{}) ([]map[string]interface{}, diag.Diagnostics) {
+			var diags diag.Diagnostics
+			if key != priorKey && findRule(rules, key) >= 0 {
+				diags.AddError(
+					"Failed to update global security policy rule",
+					fmt.Sprintf("An equivalent %s rule already exists, import it with id %s", plan.Direction.ValueString(), buildId(plan, key)),
+				)
+				return nil, diags
+			}
+			// replace the rule in place, or add it back if removed outside terraform
+			if idx := findRule(rules, priorKey); idx >= 0 {
+				rules[idx] = input
+				return rules, diags
+			}
+			return append(rules, input), diags
+		})...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.RuleKey = types.StringValue(key)
+	plan.Id = types.StringValue(buildId(plan, key))
+	resp.Diagnostics.Append(r.readRule(ctx, plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *GlobalSecurityPolicyRuleResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key := data.RuleKey.ValueString()
+	resp.Diagnostics.Append(r.updateRules(ctx, data.ServiceId.ValueString(), data.Direction.ValueString(),
+		func(rules []map[string]interface{}) ([]map[string]interface{}, diag.Diagnostics) {
+			remained := make([]map[string]interface{}, 0, len(rules))
+			for _, rule := range rules {
+				if network_policy_helper.PeerRuleInputKey(rule) != key {
+					remained = append(remained, rule)
+				}
+			}
+			return remained, nil
+		})...)
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[2] == "" || (parts[1] != DirectionIngress && parts[1] != DirectionEgress) {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: service_id/INGRESS|EGRESS/rule_key. Got: %q", req.ID),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("service_id"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("direction"), parts[1])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("rule_key"), parts[2])...)
+}
+
+// updateRules rewrites rules of one direction of service's global whitelist under the service lock,
+// edit receives the rules as read and returns the rules to write, rules of the other direction are kept.
+func (r *Resource) updateRules(ctx context.Context, serviceId string, direction string,
+	edit func(rules []map[string]interface{}) ([]map[string]interface{}, diag.Diagnostics)) diag.Diagnostics {
+	unlock := r.client.LockService(serviceId)
+	defer unlock()
+
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, serviceId)
+	if diags.HasError() {
+		return diags
+	}
+	if jService == nil {
+		diags.AddError(
+			"Failed to update global security policy rule",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return diags
+	}
+	jingress := jService.Get("global_whitelist.ingress")
+	jegress := jService.Get("global_whitelist.egress")
+	whitelist := map[string]interface{}{
+		"enable":  jService.Get("global_whitelist.enable").Bool(),
+		"ingress": network_policy_helper.ReadGqlResultToPeerRuleInputs(&jingress),
+		"egress":  network_policy_helper.ReadGqlResultToPeerRuleInputs(&jegress),
+	}
+	field := strings.ToLower(direction)
+	rules, d := edit(whitelist[field].([]map[string]interface{}))
+	diags.Append(d...)
+	if diags.HasError() {
+		return diags
+	}
+	whitelist[field] = rules
+
+	_, headers, err := r.client.DgqlApi.Raw(ctx, updateGlobalWhiteListDocument, "updateEverouteClusterGlobalWhitelist", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+		"data": map[string]interface{}{
+			"global_whitelist": whitelist,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to update global security policy rule",
+			fmt.Sprintf("Failed to update everoute service global whitelist: %s", err),
+		)
+		return diags
+	}
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		diags.AddError(
+			"Failed to update global security policy rule",
+			fmt.Sprintf("Failed to update everoute service global whitelist: %s", err),
+		)
+	}
+	return diags
+}
+
+func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var peerType types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rule").AtName("type"), &peerType)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// a vm peer is expanded into a rule per ip of the vm, while the resource manages a single rule of the whitelist
+	if peerType.ValueString() == network_policy_helper.PeerTypeVM {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rule").AtName("type"),
+			"Unsupported global security policy rule peer",
+			fmt.Sprintf("Peer type %s is not supported by global security policy rule, use %s with the vm's ips instead",
+				network_policy_helper.PeerTypeVM, network_policy_helper.PeerTypeIPBlock),
+		)
+	}
+}
+
+func (r *Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// resource is being created or destroyed
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+	var plan, state types.Object
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("rule"), &plan)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("rule"), &state)...)
+	if resp.Diagnostics.HasError() || plan.Equal(state) {
+		return
+	}
+	// key of the changed rule is known after its label ids are resolved on apply
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("rule_key"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), types.StringUnknown())...)
+}
+
+// readRule reads the rule of data's key after written, filling values of the planned rule not known until applied.
+func (r *Resource) readRule(ctx context.Context, data *GlobalSecurityPolicyRuleResourceModel) diag.Diagnostics {
+	jService, diags := getGlobalWhitelistGqlResult(ctx, r.client, data.ServiceId.ValueString())
+	if diags.HasError() {
+		return diags
+	}
+	idx := -1
+	var jrules gjson.Result
+	if jService != nil {
+		jrules = jService.Get(whitelistPath(data.Direction.ValueString()))
+		idx = findRule(network_policy_helper.ReadGqlResultToPeerRuleInputs(&jrules), data.RuleKey.ValueString())
+	}
+	if idx < 0 {
+		diags.AddError(
+			"Failed to read global security policy rule",
+			fmt.Sprintf("Global security policy rule %s not found after written", data.Id.ValueString()),
+		)
+		return diags
+	}
+	network_policy_helper.FillUnknownPeerRule(data.Rule, &network_policy_helper.ReadGqlResultToPeerRules(&jrules)[idx])
+	return diags
+}
+
+// getGlobalWhitelistGqlResult returns nil result without error when everoute service not found.
+func getGlobalWhitelistGqlResult(ctx context.Context, client *everoute.Client, serviceId string) (*gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getGlobalWhitelistDocument, "getEverouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to get everoute service global whitelist",
+			fmt.Sprintf("Failed to get everoute service global whitelist: %s", err),
+		)
+		return nil, diags
+	}
+	jService := result.Get("everouteClusters.0")
+	if jService.Type == gjson.Null {
+		return nil, diags
+	}
+	return &jService, diags
+}
+
+// findRule returns index of the rule of key, -1 if not found.
+func findRule(rules []map[string]interface{}, key string) int {
+	for idx, rule := range rules {
+		if network_policy_helper.PeerRuleInputKey(rule) == key {
+			return idx
+		}
+	}
+	return -1
+}
+
+func whitelistPath(direction string) string {
+	return "global_whitelist." + strings.ToLower(direction)
+}
+
+func buildId(data *GlobalSecurityPolicyRuleResourceModel, key string) string {
+	return data.ServiceId.ValueString() + "/" + data.Direction.ValueString() + "/" + key
+}
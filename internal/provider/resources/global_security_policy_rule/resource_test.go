@@ -0,0 +1,264 @@
+package global_security_policy_rule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute/fake_cloudtower"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+// fakeWhitelists holds global whitelists of everoute services, reads are delayed so that
+// concurrent read-modify-write updates overlap unless they are serialized.
+type fakeWhitelists struct {
+	mu        sync.Mutex
+	services  map[string]interface{}
+	readDelay time.Duration
+}
+
+func newTestResource(t *testing.T, serviceIds ...string) (*Resource, *fakeWhitelists) {
+	whitelists := &fakeWhitelists{services: make(map[string]interface{}), readDelay: 20 * time.Millisecond}
+	for _, id := range serviceIds {
+		whitelists.services[id] = map[string]interface{}{
+			"enable":  true,
+			"ingress": []interface{}{},
+			"egress":  []interface{}{testRuleInput("192.168.0.1")},
+		}
+	}
+	server := fake_cloudtower.NewServer(t)
+	server.Handle("getEverouteClusters", func(variables gjson.Result) (interface{}, error) {
+		id := variables.Get("where.id").String()
+		whitelists.mu.Lock()
+		whitelist, ok := whitelists.services[id]
+		whitelists.mu.Unlock()
+		time.Sleep(whitelists.readDelay)
+		clusters := []interface{}{}
+		if ok {
+			clusters = append(clusters, map[string]interface{}{"id": id, "global_whitelist": whitelist})
+		}
+		return map[string]interface{}{"everouteClusters": clusters}, nil
+	})
+	server.Handle("updateEverouteClusterGlobalWhitelist", func(variables gjson.Result) (interface{}, error) {
+		id := variables.Get("where.id").String()
+		whitelists.mu.Lock()
+		defer whitelists.mu.Unlock()
+		if _, ok := whitelists.services[id]; !ok {
+			return nil, fmt.Errorf("everoute service %s not found", id)
+		}
+		whitelists.services[id] = variables.Get("data.global_whitelist").Value()
+		return map[string]interface{}{"updateEverouteClusterGlobalWhitelist": map[string]interface{}{"id": id}}, nil
+	})
+	return &Resource{client: server.Client(t)}, whitelists
+}
+
+func (w *fakeWhitelists) rules(t *testing.T, serviceId string, direction string) []gjson.Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data, err := json.Marshal(w.services[serviceId])
+	if err != nil {
+		t.Fatalf("failed to marshal whitelist: %s", err)
+	}
+	return gjson.GetBytes(data, direction).Array()
+}
+
+func testRuleInput(ipBlock string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":            "IP_BLOCK",
+		"ip_block":        ipBlock,
+		"except_ip_block": []string{},
+		"services":        []string{},
+		"ports":           []map[string]interface{}{{"protocol": "TCP", "port": "22"}},
+	}
+}
+
+func addRule(input map[string]interface{}) func([]map[string]interface{}) ([]map[string]interface{}, diag.Diagnostics) {
+	return func(rules []map[string]interface{}) ([]map[string]interface{}, diag.Diagnostics) {
+		return append(rules, input), nil
+	}
+}
+
+func TestUpdateRulesKeepsConcurrentEditsOfOneService(t *testing.T) {
+	r, whitelists := newTestResource(t, "service-1")
+	var wg sync.WaitGroup
+	errs := make(chan diag.Diagnostics, 5)
+	for i := 1; i <= 5; i++ {
+		input := testRuleInput(fmt.Sprintf("10.0.0.%d", i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- r.updateRules(context.Background(), "service-1", DirectionIngress, addRule(input))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for diags := range errs {
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+	}
+	if rules := whitelists.rules(t, "service-1", "ingress"); len(rules) != 5 {
+		t.Errorf("got %d ingress rules, want all 5 concurrently added rules", len(rules))
+	}
+	if rules := whitelists.rules(t, "service-1", "egress"); len(rules) != 1 || rules[0].Get("ip_block").String() != "192.168.0.1" {
+		t.Errorf("egress rules = %v, want the rule of the other direction kept", rules)
+	}
+}
+
+func TestUpdateRulesOfServicesAreIndependent(t *testing.T) {
+	r, whitelists := newTestResource(t, "service-1", "service-2")
+	// an edit in progress on service-1 must not block service-2
+	unlock := r.client.LockService("service-1")
+	defer unlock()
+	done := make(chan diag.Diagnostics, 1)
+	go func() {
+		done <- r.updateRules(context.Background(), "service-2", DirectionIngress, addRule(testRuleInput("10.0.0.1")))
+	}()
+	select {
+	case diags := <-done:
+		if diags.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", diags)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("update of service-2 is blocked by the lock of service-1")
+	}
+	if rules := whitelists.rules(t, "service-2", "ingress"); len(rules) != 1 {
+		t.Errorf("got %d ingress rules of service-2, want 1", len(rules))
+	}
+	if rules := whitelists.rules(t, "service-1", "ingress"); len(rules) != 0 {
+		t.Errorf("got %d ingress rules of service-1, want it untouched", len(rules))
+	}
+}
+
+func TestReadRuleFillsSelectorIds(t *testing.T) {
+	r, whitelists := newTestResource(t, "service-1")
+	rule := map[string]interface{}{
+		"type":            "SELECTOR",
+		"selector":        []map[string]interface{}{{"id": "label-1", "key": "env", "value": "prod"}},
+		"except_ip_block": []string{},
+		"services":        []string{},
+		"ports":           []map[string]interface{}{{"protocol": "TCP", "port": "22"}},
+	}
+	whitelists.services["service-1"] = map[string]interface{}{
+		"enable":  true,
+		"ingress": []interface{}{rule},
+		"egress":  []interface{}{},
+	}
+	jrules := gjson.Parse(`[` + mustMarshal(t, rule) + `]`)
+	key := network_policy_helper.PeerRuleInputKey(network_policy_helper.ReadGqlResultToPeerRuleInputs(&jrules)[0])
+	planned := network_policy_helper.ReadGqlResultToPeerRules(&jrules)[0]
+	// ids of labels selected by key and value are unknown until applied
+	planned.Selectors[0].Id = types.StringUnknown()
+	data := &GlobalSecurityPolicyRuleResourceModel{
+		ServiceId: types.StringValue("service-1"),
+		Direction: types.StringValue(DirectionIngress),
+		RuleKey:   types.StringValue(key),
+		Rule:      &planned,
+	}
+	if diags := r.readRule(context.Background(), data); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if id := data.Rule.Selectors[0].Id; id.ValueString() != "label-1" {
+		t.Errorf("selector id = %v, want label-1", id)
+	}
+
+	data.RuleKey = types.StringValue("missing")
+	if diags := r.readRule(context.Background(), data); !diags.HasError() {
+		t.Error("rule missing after written is not reported")
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal: %s", err)
+	}
+	return string(data)
+}
+
+func testRuleModel(t *testing.T) *GlobalSecurityPolicyRuleResourceModel {
+	jrules := gjson.Parse(`[` + mustMarshal(t, testRuleInput("10.0.0.1")) + `]`)
+	rule := network_policy_helper.ReadGqlResultToPeerRules(&jrules)[0]
+	key := network_policy_helper.PeerRuleInputKey(network_policy_helper.ReadGqlResultToPeerRuleInputs(&jrules)[0])
+	data := &GlobalSecurityPolicyRuleResourceModel{
+		ServiceId: types.StringValue("service-1"),
+		Direction: types.StringValue(DirectionIngress),
+		Rule:      &rule,
+		RuleKey:   types.StringValue(key),
+	}
+	data.Id = types.StringValue(buildId(data, key))
+	return data
+}
+
+func testSchema(t *testing.T, r *Resource) schema.Schema {
+	resp := &resource.SchemaResponse{}
+	r.Schema(context.Background(), resource.SchemaRequest{}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	return resp.Schema
+}
+
+func testPlan(t *testing.T, s schema.Schema, data *GlobalSecurityPolicyRuleResourceModel) tfsdk.Plan {
+	plan := tfsdk.Plan{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+	if diags := plan.Set(context.Background(), data); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	return plan
+}
+
+func TestValidateConfigRejectsVmPeer(t *testing.T) {
+	r := &Resource{}
+	s := testSchema(t, r)
+	for peerType, wantError := range map[string]bool{
+		network_policy_helper.PeerTypeIPBlock: false,
+		network_policy_helper.PeerTypeVM:      true,
+	} {
+		data := testRuleModel(t)
+		data.Rule.Type = types.StringValue(peerType)
+		req := resource.ValidateConfigRequest{Config: tfsdk.Config(testPlan(t, s, data))}
+		resp := &resource.ValidateConfigResponse{}
+		r.ValidateConfig(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() != wantError {
+			t.Errorf("type %s: diagnostics = %v, want error %v", peerType, resp.Diagnostics, wantError)
+		}
+	}
+}
+
+func TestModifyPlanUnknownKeyOfChangedRule(t *testing.T) {
+	r := &Resource{}
+	s := testSchema(t, r)
+	state := testRuleModel(t)
+	for name, changed := range map[string]bool{"unchanged": false, "changed": true} {
+		planned := testRuleModel(t)
+		if changed {
+			planned.Rule.IPBlock = ip_helper.NewIPBlockValue("10.0.0.2")
+		}
+		req := resource.ModifyPlanRequest{
+			State: tfsdk.State(testPlan(t, s, state)),
+			Plan:  testPlan(t, s, planned),
+		}
+		resp := &resource.ModifyPlanResponse{Plan: req.Plan}
+		r.ModifyPlan(context.Background(), req, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+		}
+		var key types.String
+		resp.Plan.GetAttribute(context.Background(), path.Root("rule_key"), &key)
+		if key.IsUnknown() != changed {
+			t.Errorf("%s rule: rule_key = %v, want unknown %v", name, key, changed)
+		}
+	}
+}
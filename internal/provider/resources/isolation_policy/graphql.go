@@ -0,0 +1,80 @@
+package isolation_policy
+
+var getIsolationPolicyDocument = `
+query isolationPolicies($where: IsolationPolicyWhereInput) {
+	isolationPolicies(where: $where, first: 1) {
+	  id
+	  mode
+	  everoute_cluster {
+		id
+	  }
+	  vm {
+		id
+		name
+		status
+		ips
+	  }
+	  ingress {
+		type
+		ip_block
+		except_ip_block
+		services
+		ports {
+		  port
+		  protocol
+		  alg_protocol
+		  icmp_type
+		  icmp_code
+		}
+	  }
+	  egress {
+		type
+		ip_block
+		except_ip_block
+		services
+		ports {
+		  port
+		  protocol
+		  alg_protocol
+		  icmp_type
+		  icmp_code
+		}
+	  }
+	}
+  }
+`
+
+var getServiceDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	}
+  }
+`
+
+var createIsolationPolicyDocument = `
+mutation createIsolationPolicy($data: IsolationPolicyCreateInput!) {
+	createIsolationPolicy(data: $data) {
+	  id
+	}
+  }
+`
+
+var updateIsolationPolicyDocument = `
+mutation updateIsolationPolicy(
+	$where: IsolationPolicyWhereUniqueInput!
+	$data: IsolationPolicyUpdateInput!
+  ) {
+	updateIsolationPolicy(where: $where, data: $data) {
+	  id
+	}
+  }
+`
+
+var deleteIsolationPolicyDocument = `
+mutation deleteIsolationPolicy($where: IsolationPolicyWhereUniqueInput!) {
+	deleteIsolationPolicy(where: $where) {
+	  id
+	}
+  }
+`
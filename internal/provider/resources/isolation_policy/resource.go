@@ -0,0 +1,450 @@
+package isolation_policy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/vm_helper"
+	"github.com/tidwall/gjson"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Resource{}
+var _ resource.ResourceWithImportState = &Resource{}
+var _ resource.ResourceWithValidateConfig = &Resource{}
+
+const (
+	IsolationModeAll     = "ALL"
+	IsolationModePartial = "PARTIAL"
+)
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+// Resource defines the resource implementation.
+type Resource struct {
+	client *everoute.Client
+}
+
+// IsolationPolicyResourceModel describes the resource data model.
+type IsolationPolicyResourceModel struct {
+	Id        types.String                                   `tfsdk:"id"`
+	ServiceId types.String                                   `tfsdk:"service_id"`
+	VmId      types.String                                   `tfsdk:"vm_id"`
+	Vm        types.Object                                   `tfsdk:"vm"`
+	Mode      types.String                                   `tfsdk:"mode"`
+	Ingress   []network_policy_helper.NetworkPolicyRuleModel `tfsdk:"ingress"`
+	Egress    []network_policy_helper.NetworkPolicyRuleModel `tfsdk:"egress"`
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_isolation_policy"
+}
+
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "everoute isolation policy quarantining a vm",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "isolation policy's identifier",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id isolation policy belongs to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vm_id": schema.StringAttribute{
+				MarkdownDescription: "isolated vm's id",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"vm": vm_helper.VmResourceSchema(),
+			"mode": schema.StringAttribute{
+				MarkdownDescription: "isolation mode, valid value: ALL, PARTIAL. ALL isolates the vm completely, PARTIAL only allows traffic matching ingress and egress",
+				Required:            true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(IsolationModeAll, IsolationModePartial),
+				},
+			},
+			"ingress": schema.ListNestedAttribute{
+				MarkdownDescription: "allowed ingress exceptions, only valid in PARTIAL mode",
+				Optional:            true,
+				NestedObject:        network_policy_helper.NetworkPolicyRuleSchema(),
+			},
+			"egress": schema.ListNestedAttribute{
+				MarkdownDescription: "allowed egress exceptions, only valid in PARTIAL mode",
+				Optional:            true,
+				NestedObject:        network_policy_helper.NetworkPolicyRuleSchema(),
+			},
+		},
+	}
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *IsolationPolicyResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// precheck everoute service and vm existed
+	serviceId := data.ServiceId.ValueString()
+	result, _, err := r.client.DgqlApi.Raw(ctx, getServiceDocument, "everouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create isolation policy",
+			fmt.Sprintf("Failed to get everoute service: %s", err),
+		)
+		return
+	}
+	if result.Get("everouteClusters.0").Type == gjson.Null {
+		resp.Diagnostics.AddError(
+			"Failed to create isolation policy",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return
+	}
+	vmId := data.VmId.ValueString()
+	vm, diags := vm_helper.GetVmById(r.client.Api, vmId)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if vm == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create isolation policy",
+			fmt.Sprintf("Vm %s not found", vmId),
+		)
+		return
+	}
+
+	input, diags := buildPolicyInput(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	input["everoute_cluster"] = map[string]interface{}{
+		"connect": map[string]interface{}{
+			"id": serviceId,
+		},
+	}
+	input["vm"] = map[string]interface{}{
+		"connect": map[string]interface{}{
+			"id": *vm.ID,
+		},
+	}
+	result, headers, err := r.client.DgqlApi.Raw(ctx, createIsolationPolicyDocument, "createIsolationPolicy", map[string]interface{}{
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create isolation policy",
+			fmt.Sprintf("Failed to create everoute isolation policy: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create isolation policy",
+			fmt.Sprintf("Failed to create everoute isolation policy: %s", err),
+		)
+		return
+	}
+
+	id := result.Get("createIsolationPolicy.id").String()
+	jPolicy, diags := getIsolationPolicyGqlResult(ctx, r.client, id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jPolicy == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create isolation policy",
+			fmt.Sprintf("Everoute isolation policy %s not found after created", id),
+		)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, jPolicy, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *IsolationPolicyResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	jPolicy, diags := getIsolationPolicyGqlResult(ctx, r.client, data.Id.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// isolation policy deleted outside terraform
+	if jPolicy == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, jPolicy, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state *IsolationPolicyResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Id.ValueString()
+	input, diags := buildPolicyInput(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, headers, err := r.client.DgqlApi.Raw(ctx, updateIsolationPolicyDocument, "updateIsolationPolicy", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update isolation policy",
+			fmt.Sprintf("Failed to update everoute isolation policy: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update isolation policy",
+			fmt.Sprintf("Failed to update everoute isolation policy: %s", err),
+		)
+		return
+	}
+
+	jPolicy, diags := getIsolationPolicyGqlResult(ctx, r.client, id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jPolicy == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update isolation policy",
+			fmt.Sprintf("Everoute isolation policy %s not found after updated", id),
+		)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, jPolicy, plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *IsolationPolicyResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, headers, err := r.client.DgqlApi.Raw(ctx, deleteIsolationPolicyDocument, "deleteIsolationPolicy", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": data.Id.ValueString(),
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete isolation policy",
+			fmt.Sprintf("Failed to delete everoute isolation policy: %s", err),
+		)
+		return
+	}
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete isolation policy",
+			fmt.Sprintf("Failed to delete everoute isolation policy: %s", err),
+		)
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data *IsolationPolicyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if data.Mode.ValueString() == IsolationModeAll {
+		if len(data.Ingress) != 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ingress"),
+				"Invalid ingress",
+				"ingress is not allowed when isolation mode is ALL",
+			)
+		}
+		if len(data.Egress) != 0 {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("egress"),
+				"Invalid egress",
+				"egress is not allowed when isolation mode is ALL",
+			)
+		}
+	}
+}
+
+// getIsolationPolicyGqlResult returns nil result without error when isolation policy not found.
+func getIsolationPolicyGqlResult(ctx context.Context, client *everoute.Client, id string) (*gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getIsolationPolicyDocument, "isolationPolicies", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to get everoute isolation policy",
+			fmt.Sprintf("Failed to get everoute isolation policy: %s", err),
+		)
+		return nil, diags
+	}
+	jPolicy := result.Get("isolationPolicies.0")
+	if jPolicy.Type == gjson.Null {
+		return nil, diags
+	}
+	return &jPolicy, diags
+}
+
+func buildPolicyInput(ctx context.Context, data *IsolationPolicyResourceModel) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	ingress := make([]map[string]interface{}, 0)
+	egress := make([]map[string]interface{}, 0)
+	// exceptions only take effect in partial mode
+	if data.Mode.ValueString() == IsolationModePartial {
+		for _, rule := range data.Ingress {
+			rule := rule
+			input, d := network_policy_helper.BuildNetworkPolicyRuleInput(ctx, &rule)
+			diags.Append(d...)
+			ingress = append(ingress, input)
+		}
+		for _, rule := range data.Egress {
+			rule := rule
+			input, d := network_policy_helper.BuildNetworkPolicyRuleInput(ctx, &rule)
+			diags.Append(d...)
+			egress = append(egress, input)
+		}
+	}
+	return map[string]interface{}{
+		"mode":    data.Mode.ValueString(),
+		"ingress": ingress,
+		"egress":  egress,
+	}, diags
+}
+
+func readGqlResultToState(ctx context.Context, input *gjson.Result, state *IsolationPolicyResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	state.Id = types.StringValue(input.Get("id").String())
+	state.ServiceId = types.StringValue(input.Get("everoute_cluster.id").String())
+	state.Mode = types.StringValue(input.Get("mode").String())
+
+	jVm := input.Get("vm")
+	var vm vm_helper.VmModel
+	vm_helper.ReadGJsonVmToModel(&jVm, &vm)
+	state.VmId = vm.Id
+	state.Vm, diags = types.ObjectValueFrom(ctx, vm_helper.VmAttrTypes(), vm)
+
+	// keep ingress and egress null if they are not configured and empty
+	jIngress := input.Get("ingress")
+	if state.Ingress != nil || len(jIngress.Array()) > 0 {
+		state.Ingress = network_policy_helper.ReadGqlResultToNetworkPolicyRule(&jIngress)
+	}
+	jEgress := input.Get("egress")
+	if state.Egress != nil || len(jEgress.Array()) > 0 {
+		state.Egress = network_policy_helper.ReadGqlResultToNetworkPolicyRule(&jEgress)
+	}
+	return diags
+}
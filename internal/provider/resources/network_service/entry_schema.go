@@ -0,0 +1,142 @@
+package network_service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/port_helper"
+	"github.com/tidwall/gjson"
+)
+
+type EntryModel struct {
+	Protocol types.String         `tfsdk:"protocol"`
+	Port     port_helper.PortSpec `tfsdk:"port"`
+	ICMPType types.Int64          `tfsdk:"icmp_type"`
+}
+
+func entrySchema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: "protocol and port entries of the network service",
+		Required:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"protocol": schema.StringAttribute{
+					MarkdownDescription: "entry's protocol, valid value: TCP, UDP, ICMP",
+					Required:            true,
+					Validators: []validator.String{
+						stringvalidator.OneOf("TCP", "UDP", "ICMP"),
+					},
+				},
+				"port": schema.StringAttribute{
+					MarkdownDescription: "entry's ports and port ranges like 80,8000-8080, only for TCP and UDP, leave it unset for all ports",
+					CustomType:          port_helper.PortSpecType{},
+					Optional:            true,
+					Validators: []validator.String{
+						port_helper.GetPortSpecValidator(),
+					},
+				},
+				"icmp_type": schema.Int64Attribute{
+					MarkdownDescription: "entry's icmp type, only for ICMP, leave it unset for all icmp types",
+					Optional:            true,
+					Validators: []validator.Int64{
+						int64validator.Between(0, 255),
+					},
+				},
+			},
+			Validators: []validator.Object{
+				EntryValidator{},
+			},
+		},
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+		},
+	}
+}
+
+var _ validator.Object = EntryValidator{}
+
+type EntryValidator struct{}
+
+func (v EntryValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v EntryValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate network service entry, port is only for TCP and UDP, icmp_type is only for ICMP"
+}
+
+func (v EntryValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	attrs := req.ConfigValue.Attributes()
+	protocol, _ := attrs["protocol"].(types.String)
+	if protocol.IsNull() || protocol.IsUnknown() {
+		return
+	}
+	port, _ := attrs["port"].(port_helper.PortSpec)
+	icmpType, _ := attrs["icmp_type"].(types.Int64)
+	if protocol.ValueString() == "ICMP" && !port.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"invalid network service entry",
+			fmt.Sprintf("port is not allowed when protocol is %s", protocol.ValueString()),
+		)
+	}
+	if protocol.ValueString() != "ICMP" && !icmpType.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"invalid network service entry",
+			fmt.Sprintf("icmp_type is not allowed when protocol is %s", protocol.ValueString()),
+		)
+	}
+}
+
+func buildEntriesInput(entries []EntryModel) ([]map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	inputs := make([]map[string]interface{}, 0, len(entries))
+	for _, e := range entries {
+		input := map[string]interface{}{
+			"protocol": e.Protocol.ValueString(),
+		}
+		if !e.Port.IsNull() {
+			spec, err := port_helper.NormalizePortSpec(e.Port.ValueString())
+			if err != nil {
+				diags.AddError("invalid ports", err.Error())
+			}
+			input["port"] = spec
+		}
+		if !e.ICMPType.IsNull() {
+			input["icmp_type"] = e.ICMPType.ValueInt64()
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, diags
+}
+
+func readGqlResultToEntries(input *gjson.Result) []EntryModel {
+	jentries := input.Array()
+	result := make([]EntryModel, len(jentries))
+	for idx, je := range jentries {
+		e := EntryModel{
+			Protocol: types.StringValue(je.Get("protocol").String()),
+			Port:     port_helper.NewPortSpecNull(),
+			ICMPType: types.Int64Null(),
+		}
+		if jp := je.Get("port"); jp.Type != gjson.Null && jp.String() != "" {
+			e.Port = port_helper.NewPortSpecValue(jp.String())
+		}
+		if jt := je.Get("icmp_type"); jt.Type != gjson.Null {
+			e.ICMPType = types.Int64Value(jt.Int())
+		}
+		result[idx] = e
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package network_service
+
+var getNetworkServiceDocument = `
+query networkPolicyRuleServices($where: NetworkPolicyRuleServiceWhereInput) {
+	networkPolicyRuleServices(where: $where, first: 1) {
+	  id
+	  name
+	  description
+	  everoute_cluster {
+		id
+	  }
+	  members {
+		protocol
+		port
+		icmp_type
+	  }
+	}
+  }
+`
+
+var getServiceDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	}
+  }
+`
+
+var createNetworkServiceDocument = `
+mutation createNetworkPolicyRuleService($data: NetworkPolicyRuleServiceCreateInput!) {
+	createNetworkPolicyRuleService(data: $data) {
+	  id
+	  name
+	}
+  }
+`
+
+var updateNetworkServiceDocument = `
+mutation updateNetworkPolicyRuleService(
+	$where: NetworkPolicyRuleServiceWhereUniqueInput!
+	$data: NetworkPolicyRuleServiceUpdateInput!
+  ) {
+	updateNetworkPolicyRuleService(where: $where, data: $data) {
+	  id
+	  name
+	}
+  }
+`
+
+var deleteNetworkServiceDocument = `
+mutation deleteNetworkPolicyRuleService($where: NetworkPolicyRuleServiceWhereUniqueInput!) {
+	deleteNetworkPolicyRuleService(where: $where) {
+	  id
+	}
+  }
+`
@@ -0,0 +1,340 @@
+package network_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/tidwall/gjson"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Resource{}
+var _ resource.ResourceWithImportState = &Resource{}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+// Resource defines the resource implementation.
+type Resource struct {
+	client *everoute.Client
+}
+
+// NetworkServiceResourceModel describes the resource data model.
+type NetworkServiceResourceModel struct {
+	Id          types.String `tfsdk:"id"`
+	ServiceId   types.String `tfsdk:"service_id"`
+	Name        types.String `tfsdk:"name"`
+	Description types.String `tfsdk:"description"`
+	Entries     []EntryModel `tfsdk:"entries"`
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_network_service"
+}
+
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "everoute network service, a reusable set of protocol and port entries referenced by network policy rules",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "network service's identifier",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id network service belongs to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "network service's name",
+				Required:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "network service's description",
+				Default:             stringdefault.StaticString(""),
+				Optional:            true,
+				Computed:            true,
+			},
+			"entries": entrySchema(),
+		},
+	}
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *NetworkServiceResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// precheck everoute service existed
+	serviceId := data.ServiceId.ValueString()
+	result, _, err := r.client.DgqlApi.Raw(ctx, getServiceDocument, "everouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create network service",
+			fmt.Sprintf("Failed to get everoute service: %s", err),
+		)
+		return
+	}
+	if result.Get("everouteClusters.0").Type == gjson.Null {
+		resp.Diagnostics.AddError(
+			"Failed to create network service",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return
+	}
+
+	input, diags := buildNetworkServiceInput(data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	input["everoute_cluster"] = map[string]interface{}{
+		"connect": map[string]interface{}{
+			"id": serviceId,
+		},
+	}
+	result, headers, err := r.client.DgqlApi.Raw(ctx, createNetworkServiceDocument, "createNetworkPolicyRuleService", map[string]interface{}{
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create network service",
+			fmt.Sprintf("Failed to create everoute network service: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create network service",
+			fmt.Sprintf("Failed to create everoute network service: %s", err),
+		)
+		return
+	}
+
+	id := result.Get("createNetworkPolicyRuleService.id").String()
+	jService, diags := getNetworkServiceGqlResult(ctx, r.client, map[string]interface{}{"id": id})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jService == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create network service",
+			fmt.Sprintf("Everoute network service %s not found after created", id),
+		)
+		return
+	}
+	readGqlResultToState(jService, data)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *NetworkServiceResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	jService, diags := getNetworkServiceGqlResult(ctx, r.client, map[string]interface{}{"id": data.Id.ValueString()})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// network service deleted outside terraform
+	if jService == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	readGqlResultToState(jService, data)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state *NetworkServiceResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Id.ValueString()
+	input, diags := buildNetworkServiceInput(plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, headers, err := r.client.DgqlApi.Raw(ctx, updateNetworkServiceDocument, "updateNetworkPolicyRuleService", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update network service",
+			fmt.Sprintf("Failed to update everoute network service: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update network service",
+			fmt.Sprintf("Failed to update everoute network service: %s", err),
+		)
+		return
+	}
+
+	jService, diags := getNetworkServiceGqlResult(ctx, r.client, map[string]interface{}{"id": id})
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jService == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update network service",
+			fmt.Sprintf("Everoute network service %s not found after updated", id),
+		)
+		return
+	}
+	readGqlResultToState(jService, plan)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *NetworkServiceResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, headers, err := r.client.DgqlApi.Raw(ctx, deleteNetworkServiceDocument, "deleteNetworkPolicyRuleService", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": data.Id.ValueString(),
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete network service",
+			fmt.Sprintf("Failed to delete everoute network service: %s", err),
+		)
+		return
+	}
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete network service",
+			fmt.Sprintf("Failed to delete everoute network service: %s", err),
+		)
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// getNetworkServiceGqlResult returns nil result without error when network service not found.
+func getNetworkServiceGqlResult(ctx context.Context, client *everoute.Client, where map[string]interface{}) (*gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getNetworkServiceDocument, "networkPolicyRuleServices", map[string]interface{}{
+		"where": where,
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to get everoute network service",
+			fmt.Sprintf("Failed to get everoute network service: %s", err),
+		)
+		return nil, diags
+	}
+	jService := result.Get("networkPolicyRuleServices.0")
+	if jService.Type == gjson.Null {
+		return nil, diags
+	}
+	return &jService, diags
+}
+
+func buildNetworkServiceInput(data *NetworkServiceResourceModel) (map[string]interface{}, diag.Diagnostics) {
+	entries, diags := buildEntriesInput(data.Entries)
+	return map[string]interface{}{
+		"name":        data.Name.ValueString(),
+		"description": data.Description.ValueString(),
+		"members":     entries,
+	}, diags
+}
+
+func readGqlResultToState(input *gjson.Result, state *NetworkServiceResourceModel) {
+	state.Id = types.StringValue(input.Get("id").String())
+	state.ServiceId = types.StringValue(input.Get("everoute_cluster.id").String())
+	state.Name = types.StringValue(input.Get("name").String())
+	state.Description = types.StringValue(input.Get("description").String())
+	jEntries := input.Get("members")
+	state.Entries = readGqlResultToEntries(&jEntries)
+}
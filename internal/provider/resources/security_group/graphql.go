@@ -0,0 +1,62 @@
+package security_group
+
+var getSecurityGroupDocument = `
+query securityGroups($where: SecurityGroupWhereInput) {
+	securityGroups(where: $where, first: 1) {
+	  id
+	  name
+	  description
+	  everoute_cluster {
+		id
+	  }
+	  label_groups {
+		labels {
+		  id
+		  key
+		  value
+		}
+	  }
+	  vms {
+		id
+	  }
+	  ips
+	}
+  }
+`
+
+var getServiceDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	}
+  }
+`
+
+var createSecurityGroupDocument = `
+mutation createSecurityGroup($data: SecurityGroupCreateInput!) {
+	createSecurityGroup(data: $data) {
+	  id
+	  name
+	}
+  }
+`
+
+var updateSecurityGroupDocument = `
+mutation updateSecurityGroup(
+	$where: SecurityGroupWhereUniqueInput!
+	$data: SecurityGroupUpdateInput!
+  ) {
+	updateSecurityGroup(where: $where, data: $data) {
+	  id
+	  name
+	}
+  }
+`
+
+var deleteSecurityGroupDocument = `
+mutation deleteSecurityGroup($where: SecurityGroupWhereUniqueInput!) {
+	deleteSecurityGroup(where: $where) {
+	  id
+	}
+  }
+`
@@ -0,0 +1,60 @@
+package security_group
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/label_helper"
+	"github.com/tidwall/gjson"
+)
+
+type LabelGroupModel struct {
+	Selectors []label_helper.LabelModel `tfsdk:"selectors"`
+}
+
+func labelGroupSchema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: "vms carrying all labels of any label group are members of the security group",
+		Optional:            true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"selectors": schema.ListNestedAttribute{
+					MarkdownDescription: "labels a member vm must carry",
+					Required:            true,
+					NestedObject:        label_helper.LabelSelectorSchema(),
+					Validators: []validator.List{
+						listvalidator.SizeAtLeast(1),
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildLabelGroupIds resolves labels of every label group to label ids.
+func buildLabelGroupIds(client *everoute.Client, labelGroups []LabelGroupModel) ([][]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result := make([][]string, 0, len(labelGroups))
+	for _, lg := range labelGroups {
+		ids, d := label_helper.ResolveLabelIds(client.Api, lg.Selectors)
+		diags.Append(d...)
+		result = append(result, ids)
+	}
+	return result, diags
+}
+
+func readGqlResultToLabelGroups(input *gjson.Result) []LabelGroupModel {
+	jlgs := input.Array()
+	result := make([]LabelGroupModel, len(jlgs))
+	for idx, jlg := range jlgs {
+		for _, jl := range jlg.Get("labels").Array() {
+			jl := jl
+			var l label_helper.LabelModel
+			label_helper.ReadGJsonLabelToModel(&jl, &l)
+			result[idx].Selectors = append(result[idx].Selectors, l)
+		}
+	}
+	return result
+}
@@ -0,0 +1,502 @@
+package security_group
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/client/vm"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/models"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/ip_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/vm_helper"
+	"github.com/tidwall/gjson"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Resource{}
+var _ resource.ResourceWithImportState = &Resource{}
+var _ resource.ResourceWithConfigValidators = &Resource{}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+// Resource defines the resource implementation.
+type Resource struct {
+	client *everoute.Client
+}
+
+// SecurityGroupResourceModel describes the resource data model.
+type SecurityGroupResourceModel struct {
+	Id          types.String      `tfsdk:"id"`
+	ServiceId   types.String      `tfsdk:"service_id"`
+	Name        types.String      `tfsdk:"name"`
+	Description types.String      `tfsdk:"description"`
+	LabelGroups []LabelGroupModel `tfsdk:"label_groups"`
+	VmIds       types.List        `tfsdk:"vm_ids"`
+	IPBlocks    types.List        `tfsdk:"ip_blocks"`
+	Members     types.List        `tfsdk:"members"`
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_security_group"
+}
+
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "everoute security group, a reusable group of workloads referenced by security policies",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "security group's identifier",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id security group belongs to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "security group's name",
+				Required:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "security group's description",
+				Default:             stringdefault.StaticString(""),
+				Optional:            true,
+				Computed:            true,
+			},
+			"label_groups": labelGroupSchema(),
+			"vm_ids": schema.ListAttribute{
+				MarkdownDescription: "ids of vms explicitly added to the security group",
+				ElementType:         types.StringType,
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+				},
+			},
+			"ip_blocks": schema.ListAttribute{
+				MarkdownDescription: "ips or cidrs in the security group",
+				ElementType:         ip_helper.IPBlockType{},
+				Optional:            true,
+				Validators: []validator.List{
+					listvalidator.UniqueValues(),
+					listvalidator.ValueStringsAre(ip_helper.GetIPBlockValidator()),
+				},
+			},
+			"members": schema.ListNestedAttribute{
+				MarkdownDescription: "vms currently in the security group, selected by label groups or vm ids",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: vm_helper.VmResourceAttributes(),
+				},
+			},
+		},
+	}
+}
+
+func (r *Resource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.AtLeastOneOf(
+			path.MatchRoot("label_groups"),
+			path.MatchRoot("vm_ids"),
+			path.MatchRoot("ip_blocks"),
+		),
+	}
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *SecurityGroupResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// precheck everoute service existed
+	serviceId := data.ServiceId.ValueString()
+	result, _, err := r.client.DgqlApi.Raw(ctx, getServiceDocument, "everouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security group",
+			fmt.Sprintf("Failed to get everoute service: %s", err),
+		)
+		return
+	}
+	if result.Get("everouteClusters.0").Type == gjson.Null {
+		resp.Diagnostics.AddError(
+			"Failed to create security group",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return
+	}
+
+	input, diags := r.buildGroupInput(ctx, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	input["everoute_cluster"] = map[string]interface{}{
+		"connect": map[string]interface{}{
+			"id": serviceId,
+		},
+	}
+	result, headers, err := r.client.DgqlApi.Raw(ctx, createSecurityGroupDocument, "createSecurityGroup", map[string]interface{}{
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security group",
+			fmt.Sprintf("Failed to create everoute security group: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security group",
+			fmt.Sprintf("Failed to create everoute security group: %s", err),
+		)
+		return
+	}
+
+	id := result.Get("createSecurityGroup.id").String()
+	jGroup, diags := getSecurityGroupGqlResult(ctx, r.client, id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jGroup == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security group",
+			fmt.Sprintf("Everoute security group %s not found after created", id),
+		)
+		return
+	}
+	resp.Diagnostics.Append(r.readGqlResultToState(ctx, jGroup, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *SecurityGroupResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	jGroup, diags := getSecurityGroupGqlResult(ctx, r.client, data.Id.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// security group deleted outside terraform
+	if jGroup == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(r.readGqlResultToState(ctx, jGroup, data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state *SecurityGroupResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Id.ValueString()
+	input, diags := r.buildGroupInput(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, headers, err := r.client.DgqlApi.Raw(ctx, updateSecurityGroupDocument, "updateSecurityGroup", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update security group",
+			fmt.Sprintf("Failed to update everoute security group: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update security group",
+			fmt.Sprintf("Failed to update everoute security group: %s", err),
+		)
+		return
+	}
+
+	jGroup, diags := getSecurityGroupGqlResult(ctx, r.client, id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jGroup == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update security group",
+			fmt.Sprintf("Everoute security group %s not found after updated", id),
+		)
+		return
+	}
+	resp.Diagnostics.Append(r.readGqlResultToState(ctx, jGroup, plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *SecurityGroupResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, headers, err := r.client.DgqlApi.Raw(ctx, deleteSecurityGroupDocument, "deleteSecurityGroup", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": data.Id.ValueString(),
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete security group",
+			fmt.Sprintf("Failed to delete everoute security group: %s", err),
+		)
+		return
+	}
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete security group",
+			fmt.Sprintf("Failed to delete everoute security group: %s", err),
+		)
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// getSecurityGroupGqlResult returns nil result without error when security group not found.
+func getSecurityGroupGqlResult(ctx context.Context, client *everoute.Client, id string) (*gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getSecurityGroupDocument, "securityGroups", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to get everoute security group",
+			fmt.Sprintf("Failed to get everoute security group: %s", err),
+		)
+		return nil, diags
+	}
+	jGroup := result.Get("securityGroups.0")
+	if jGroup.Type == gjson.Null {
+		return nil, diags
+	}
+	return &jGroup, diags
+}
+
+func (r *Resource) buildGroupInput(ctx context.Context, data *SecurityGroupResourceModel) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	labelGroupIds, d := buildLabelGroupIds(r.client, data.LabelGroups)
+	diags.Append(d...)
+	labelGroups := make([]map[string]interface{}, 0, len(labelGroupIds))
+	for _, ids := range labelGroupIds {
+		labelGroups = append(labelGroups, map[string]interface{}{
+			"labels": ids,
+		})
+	}
+	vmIds := make([]string, 0)
+	if !data.VmIds.IsNull() {
+		diags.Append(data.VmIds.ElementsAs(ctx, &vmIds, false)...)
+	}
+	vms := make([]map[string]interface{}, 0, len(vmIds))
+	for _, vmId := range vmIds {
+		vms = append(vms, map[string]interface{}{
+			"id": vmId,
+		})
+	}
+	ips := make([]string, 0)
+	if !data.IPBlocks.IsNull() {
+		diags.Append(data.IPBlocks.ElementsAs(ctx, &ips, false)...)
+	}
+	return map[string]interface{}{
+		"name":         data.Name.ValueString(),
+		"description":  data.Description.ValueString(),
+		"label_groups": labelGroups,
+		"vms": map[string]interface{}{
+			"set": vms,
+		},
+		"ips": ips,
+	}, diags
+}
+
+func (r *Resource) readGqlResultToState(ctx context.Context, input *gjson.Result, state *SecurityGroupResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	state.Id = types.StringValue(input.Get("id").String())
+	state.ServiceId = types.StringValue(input.Get("everoute_cluster.id").String())
+	state.Name = types.StringValue(input.Get("name").String())
+	state.Description = types.StringValue(input.Get("description").String())
+
+	// keep membership attributes null if they are not configured and empty
+	jLabelGroups := input.Get("label_groups")
+	if state.LabelGroups != nil || len(jLabelGroups.Array()) > 0 {
+		state.LabelGroups = readGqlResultToLabelGroups(&jLabelGroups)
+	}
+	vmIds := make([]string, 0)
+	for _, jvm := range input.Get("vms").Array() {
+		vmIds = append(vmIds, jvm.Get("id").String())
+	}
+	if !state.VmIds.IsNull() || len(vmIds) > 0 {
+		state.VmIds, _ = types.ListValueFrom(ctx, types.StringType, vmIds)
+	}
+	ips := make([]string, 0)
+	for _, jip := range input.Get("ips").Array() {
+		ips = append(ips, jip.String())
+	}
+	if !state.IPBlocks.IsNull() || len(ips) > 0 {
+		state.IPBlocks = ip_helper.IPBlockListValue(ips)
+	}
+
+	members, d := r.readMembers(ctx, input)
+	diags.Append(d...)
+	state.Members = members
+	return diags
+}
+
+// readMembers lists vms selected by explicit vm ids or matching all labels of any label group.
+func (r *Resource) readMembers(ctx context.Context, input *gjson.Result) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	memberType := types.ObjectType{AttrTypes: vm_helper.VmAttrTypes()}
+	where := make([]*models.VMWhereInput, 0)
+	vmIds := make([]string, 0)
+	for _, jvm := range input.Get("vms").Array() {
+		vmIds = append(vmIds, jvm.Get("id").String())
+	}
+	if len(vmIds) > 0 {
+		where = append(where, &models.VMWhereInput{
+			IDIn: vmIds,
+		})
+	}
+	for _, jlg := range input.Get("label_groups").Array() {
+		and := make([]*models.VMWhereInput, 0)
+		for _, jl := range jlg.Get("labels").Array() {
+			id := jl.Get("id").String()
+			and = append(and, &models.VMWhereInput{
+				LabelsSome: &models.LabelWhereInput{
+					ID: &id,
+				},
+			})
+		}
+		if len(and) > 0 {
+			where = append(where, &models.VMWhereInput{
+				AND: and,
+			})
+		}
+	}
+	members := make([]attr.Value, 0)
+	if len(where) == 0 {
+		return types.ListValueMust(memberType, members), diags
+	}
+	gvp := vm.NewGetVmsParams()
+	gvp.RequestBody = &models.GetVmsRequestBody{
+		Where: &models.VMWhereInput{
+			OR: where,
+		},
+	}
+	vms, err := r.client.Api.VM.GetVms(gvp)
+	if err != nil {
+		diags.AddError("Failed to read security group members", fmt.Sprintf("Unable to get vms, got error: %s", err))
+		return types.ListNull(memberType), diags
+	}
+	for _, v := range vms.Payload {
+		var m vm_helper.VmModel
+		vm_helper.ReadSdkVmToModel(v, &m)
+		mv, d := types.ObjectValueFrom(ctx, vm_helper.VmAttrTypes(), m)
+		diags.Append(d...)
+		members = append(members, mv)
+	}
+	return types.ListValueMust(memberType, members), diags
+}
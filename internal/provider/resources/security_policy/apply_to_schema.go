@@ -0,0 +1,114 @@
+package security_policy
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/label_helper"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+type ApplyToModel struct {
+	Type            types.String              `tfsdk:"type"`
+	Communicable    types.Bool                `tfsdk:"communicable"`
+	Selectors       []label_helper.LabelModel `tfsdk:"selectors"`
+	SecurityGroupId types.String              `tfsdk:"security_group_id"`
+}
+
+func applyToSchema() schema.Attribute {
+	return schema.ListNestedAttribute{
+		MarkdownDescription: "workloads the security policy applies to. Everoute cannot apply a security policy to vm ids directly, " +
+			"list the vms in vm_ids of an everoute_security_group and apply the policy to the group with type SECURITY_GROUP",
+		Required: true,
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"type": schema.StringAttribute{
+					MarkdownDescription: "how workloads are selected, valid value: SELECTOR, SECURITY_GROUP",
+					Default:             stringdefault.StaticString(network_policy_helper.PeerTypeSelector),
+					Optional:            true,
+					Computed:            true,
+					Validators: []validator.String{
+						stringvalidator.OneOf(network_policy_helper.PeerTypeSelector, network_policy_helper.PeerTypeSecurityGroup),
+					},
+				},
+				"communicable": schema.BoolAttribute{
+					MarkdownDescription: "if selected workloads are allowed to communicate with each other",
+					Default:             booldefault.StaticBool(true),
+					Optional:            true,
+					Computed:            true,
+				},
+				"selectors": schema.ListNestedAttribute{
+					MarkdownDescription: "labels selecting workloads, required when type is SELECTOR",
+					Optional:            true,
+					NestedObject:        label_helper.LabelSelectorSchema(),
+				},
+				"security_group_id": schema.StringAttribute{
+					MarkdownDescription: "security group's id, required when type is SECURITY_GROUP",
+					Optional:            true,
+				},
+			},
+			Validators: []validator.Object{
+				GetApplyToValidator(),
+			},
+		},
+		Validators: []validator.List{
+			listvalidator.SizeAtLeast(1),
+		},
+	}
+}
+
+func buildApplyToInput(client *everoute.Client, applyTo []ApplyToModel) ([]map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	inputs := make([]map[string]interface{}, 0, len(applyTo))
+	for _, at := range applyTo {
+		input := map[string]interface{}{
+			"type":         at.Type.ValueString(),
+			"communicable": at.Communicable.ValueBool(),
+		}
+		if at.Type.ValueString() == network_policy_helper.PeerTypeSecurityGroup {
+			input["security_group_id"] = at.SecurityGroupId.ValueString()
+			input["selector_ids"] = []string{}
+		} else {
+			ids, d := label_helper.ResolveLabelIds(client.Api, at.Selectors)
+			diags.Append(d...)
+			input["selector_ids"] = ids
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, diags
+}
+
+func readGqlResultToApplyTo(input *gjson.Result) []ApplyToModel {
+	jats := input.Array()
+	result := make([]ApplyToModel, len(jats))
+	for idx, jat := range jats {
+		at := ApplyToModel{
+			Type:            types.StringValue(jat.Get("type").String()),
+			Communicable:    types.BoolValue(jat.Get("communicable").Bool()),
+			SecurityGroupId: types.StringNull(),
+		}
+		// policies created by old version cloudtower have no type
+		if at.Type.ValueString() == "" {
+			at.Type = types.StringValue(network_policy_helper.PeerTypeSelector)
+		}
+		if at.Type.ValueString() == network_policy_helper.PeerTypeSecurityGroup {
+			at.SecurityGroupId = types.StringValue(jat.Get("security_group_id").String())
+		} else {
+			for _, jl := range jat.Get("selector").Array() {
+				jl := jl
+				var l label_helper.LabelModel
+				label_helper.ReadGJsonLabelToModel(&jl, &l)
+				at.Selectors = append(at.Selectors, l)
+			}
+		}
+		result[idx] = at
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package security_policy
+
+var getSecurityPolicyDocument = `
+query securityPolicies($where: SecurityPolicyWhereInput) {
+	securityPolicies(where: $where, first: 1) {
+	  id
+	  name
+	  description
+	  policy_mode
+	  everoute_cluster {
+		id
+	  }
+	  apply_to {
+		type
+		communicable
+		security_group_id
+		selector {
+		  id
+		  key
+		  value
+		}
+	  }
+	  ingress {
+		type
+		ip_block
+		except_ip_block
+		services
+		security_group_id
+		ports {
+		  port
+		  protocol
+		  alg_protocol
+		  icmp_type
+		  icmp_code
+		}
+		selector {
+		  id
+		  key
+		  value
+		}
+	  }
+	  egress {
+		type
+		ip_block
+		except_ip_block
+		services
+		security_group_id
+		ports {
+		  port
+		  protocol
+		  alg_protocol
+		  icmp_type
+		  icmp_code
+		}
+		selector {
+		  id
+		  key
+		  value
+		}
+	  }
+	}
+  }
+`
+
+var createSecurityPolicyDocument = `
+mutation createSecurityPolicy($data: SecurityPolicyCreateInput!) {
+	createSecurityPolicy(data: $data) {
+	  id
+	  name
+	}
+  }
+`
+
+var updateSecurityPolicyDocument = `
+mutation updateSecurityPolicy(
+	$where: SecurityPolicyWhereUniqueInput!
+	$data: SecurityPolicyUpdateInput!
+  ) {
+	updateSecurityPolicy(where: $where, data: $data) {
+	  id
+	  name
+	}
+  }
+`
+
+var deleteSecurityPolicyDocument = `
+mutation deleteSecurityPolicy($where: SecurityPolicyWhereUniqueInput!) {
+	deleteSecurityPolicy(where: $where) {
+	  id
+	}
+  }
+`
+
+var getServiceDocument = `
+query everouteClusters($where: EverouteClusterWhereInput) {
+	everouteClusters(where: $where, first: 1) {
+	  id
+	}
+  }
+`
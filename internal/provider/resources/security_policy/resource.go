@@ -0,0 +1,408 @@
+package security_policy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/cloudtower-go-sdk/v2/utils"
+	"github.com/smartxworks/terraform-provider-everoute/internal/everoute"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+	"github.com/tidwall/gjson"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &Resource{}
+var _ resource.ResourceWithImportState = &Resource{}
+var _ resource.ResourceWithValidateConfig = &Resource{}
+
+func NewResource() resource.Resource {
+	return &Resource{}
+}
+
+// Resource defines the resource implementation.
+type Resource struct {
+	client *everoute.Client
+}
+
+// SecurityPolicyResourceModel describes the resource data model.
+type SecurityPolicyResourceModel struct {
+	Id          types.String                          `tfsdk:"id"`
+	ServiceId   types.String                          `tfsdk:"service_id"`
+	Name        types.String                          `tfsdk:"name"`
+	Description types.String                          `tfsdk:"description"`
+	PolicyMode  types.String                          `tfsdk:"policy_mode"`
+	ApplyTo     []ApplyToModel                        `tfsdk:"apply_to"`
+	Ingress     []network_policy_helper.PeerRuleModel `tfsdk:"ingress"`
+	Egress      []network_policy_helper.PeerRuleModel `tfsdk:"egress"`
+}
+
+func (r *Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_security_policy"
+}
+
+func (r *Resource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		// This description is used by the documentation generator and the language server.
+		MarkdownDescription: "everoute security policy applied to selected workloads",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				MarkdownDescription: "security policy's identifier",
+				Computed:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"service_id": schema.StringAttribute{
+				MarkdownDescription: "everoute service's id security policy belongs to",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"name": schema.StringAttribute{
+				MarkdownDescription: "security policy's name",
+				Required:            true,
+			},
+			"description": schema.StringAttribute{
+				MarkdownDescription: "security policy's description",
+				Default:             stringdefault.StaticString(""),
+				Optional:            true,
+				Computed:            true,
+			},
+			"policy_mode": schema.StringAttribute{
+				MarkdownDescription: "security policy's mode, valid value: WORK, MONITOR. WORK enforces the policy, " +
+					"MONITOR only logs traffic the policy would deny, switch to WORK with the same rules once verified",
+				Default:  stringdefault.StaticString("WORK"),
+				Optional: true,
+				Computed: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf("WORK", "MONITOR"),
+				},
+			},
+			"apply_to": applyToSchema(),
+			"ingress": schema.ListNestedAttribute{
+				MarkdownDescription: "security policy's ingress rules, traffic not matched is denied",
+				Optional:            true,
+				NestedObject:        network_policy_helper.PeerRuleSchema(),
+			},
+			"egress": schema.ListNestedAttribute{
+				MarkdownDescription: "security policy's egress rules, traffic not matched is denied",
+				Optional:            true,
+				NestedObject:        network_policy_helper.PeerRuleSchema(),
+			},
+		},
+	}
+}
+
+func (r *Resource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	// Prevent panic if the provider has not been configured.
+	if req.ProviderData == nil {
+		return
+	}
+
+	client, ok := req.ProviderData.(*everoute.Client)
+
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *everoute.Client, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+
+		return
+	}
+
+	r.client = client
+}
+
+func (r *Resource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data *SecurityPolicyResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// precheck everoute service existed
+	serviceId := data.ServiceId.ValueString()
+	result, _, err := r.client.DgqlApi.Raw(ctx, getServiceDocument, "everouteClusters", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": serviceId,
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security policy",
+			fmt.Sprintf("Failed to get everoute service: %s", err),
+		)
+		return
+	}
+	if result.Get("everouteClusters.0").Type == gjson.Null {
+		resp.Diagnostics.AddError(
+			"Failed to create security policy",
+			fmt.Sprintf("Everoute service %s not found", serviceId),
+		)
+		return
+	}
+
+	input, diags := buildPolicyInput(ctx, r.client, data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	input["everoute_cluster"] = map[string]interface{}{
+		"connect": map[string]interface{}{
+			"id": serviceId,
+		},
+	}
+	result, headers, err := r.client.DgqlApi.Raw(ctx, createSecurityPolicyDocument, "createSecurityPolicy", map[string]interface{}{
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security policy",
+			fmt.Sprintf("Failed to create everoute security policy: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security policy",
+			fmt.Sprintf("Failed to create everoute security policy: %s", err),
+		)
+		return
+	}
+
+	id := result.Get("createSecurityPolicy.id").String()
+	jPolicy, diags := getSecurityPolicyGqlResult(ctx, r.client, id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jPolicy == nil {
+		resp.Diagnostics.AddError(
+			"Failed to create security policy",
+			fmt.Sprintf("Everoute security policy %s not found after created", id),
+		)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, r.client, jPolicy, data)...)
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data *SecurityPolicyResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	jPolicy, diags := getSecurityPolicyGqlResult(ctx, r.client, data.Id.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// security policy deleted outside terraform
+	if jPolicy == nil {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, r.client, jPolicy, data)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state *SecurityPolicyResourceModel
+
+	// Read Terraform plan data into the model
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id := state.Id.ValueString()
+	input, diags := buildPolicyInput(ctx, r.client, plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	_, headers, err := r.client.DgqlApi.Raw(ctx, updateSecurityPolicyDocument, "updateSecurityPolicy", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+		"data": input,
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update security policy",
+			fmt.Sprintf("Failed to update everoute security policy: %s", err),
+		)
+		return
+	}
+
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to update security policy",
+			fmt.Sprintf("Failed to update everoute security policy: %s", err),
+		)
+		return
+	}
+
+	jPolicy, diags := getSecurityPolicyGqlResult(ctx, r.client, id)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if jPolicy == nil {
+		resp.Diagnostics.AddError(
+			"Failed to update security policy",
+			fmt.Sprintf("Everoute security policy %s not found after updated", id),
+		)
+		return
+	}
+	resp.Diagnostics.Append(readGqlResultToState(ctx, r.client, jPolicy, plan)...)
+
+	// Save updated data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data *SecurityPolicyResourceModel
+
+	// Read Terraform prior state data into the model
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, headers, err := r.client.DgqlApi.Raw(ctx, deleteSecurityPolicyDocument, "deleteSecurityPolicy", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": data.Id.ValueString(),
+		},
+	}, nil)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete security policy",
+			fmt.Sprintf("Failed to delete everoute security policy: %s", err),
+		)
+		return
+	}
+	taskId := headers.Get("X-Task-Id")
+	err = utils.WaitTask(ctx, r.client.Api, &taskId, 5*time.Second)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Failed to delete security policy",
+			fmt.Sprintf("Failed to delete everoute security policy: %s", err),
+		)
+	}
+}
+
+func (r *Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (r *Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var data *SecurityPolicyResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(network_policy_helper.ValidatePeerRuleNames(data.Ingress, "ingress")...)
+	resp.Diagnostics.Append(network_policy_helper.ValidatePeerRuleNames(data.Egress, "egress")...)
+}
+
+// getSecurityPolicyGqlResult returns nil result without error when security policy not found.
+func getSecurityPolicyGqlResult(ctx context.Context, client *everoute.Client, id string) (*gjson.Result, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	result, _, err := client.DgqlApi.Raw(ctx, getSecurityPolicyDocument, "securityPolicies", map[string]interface{}{
+		"where": map[string]interface{}{
+			"id": id,
+		},
+	}, nil)
+	if err != nil {
+		diags.AddError(
+			"Failed to get everoute security policy",
+			fmt.Sprintf("Failed to get everoute security policy: %s", err),
+		)
+		return nil, diags
+	}
+	jPolicy := result.Get("securityPolicies.0")
+	if jPolicy.Type == gjson.Null {
+		return nil, diags
+	}
+	return &jPolicy, diags
+}
+
+func buildPolicyInput(ctx context.Context, client *everoute.Client, data *SecurityPolicyResourceModel) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	applyTo, d := buildApplyToInput(client, data.ApplyTo)
+	diags.Append(d...)
+	ingress, d := network_policy_helper.BuildPeerRulesInput(ctx, client.Api, data.Ingress)
+	diags.Append(d...)
+	egress, d := network_policy_helper.BuildPeerRulesInput(ctx, client.Api, data.Egress)
+	diags.Append(d...)
+	return map[string]interface{}{
+		"name":        data.Name.ValueString(),
+		"description": data.Description.ValueString(),
+		"policy_mode": data.PolicyMode.ValueString(),
+		"apply_to":    applyTo,
+		"ingress":     ingress,
+		"egress":      egress,
+	}, diags
+}
+
+func readGqlResultToState(ctx context.Context, client *everoute.Client, input *gjson.Result, state *SecurityPolicyResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	state.Id = types.StringValue(input.Get("id").String())
+	state.ServiceId = types.StringValue(input.Get("everoute_cluster.id").String())
+	state.Name = types.StringValue(input.Get("name").String())
+	state.Description = types.StringValue(input.Get("description").String())
+	state.PolicyMode = types.StringValue(input.Get("policy_mode").String())
+	// policies created by old version cloudtower have no policy mode
+	if state.PolicyMode.ValueString() == "" {
+		state.PolicyMode = types.StringValue("WORK")
+	}
+	jApplyTo := input.Get("apply_to")
+	state.ApplyTo = readGqlResultToApplyTo(&jApplyTo)
+	// keep ingress and egress null if they are not configured and empty
+	jIngress := input.Get("ingress")
+	if state.Ingress != nil || len(jIngress.Array()) > 0 {
+		ingress, d := network_policy_helper.CollapseVmPeerRules(ctx, client.Api, network_policy_helper.ReadGqlResultToPeerRules(&jIngress), state.Ingress)
+		diags.Append(d...)
+		state.Ingress = network_policy_helper.KeepPeerRuleNames(ctx, ingress, state.Ingress)
+	}
+	jEgress := input.Get("egress")
+	if state.Egress != nil || len(jEgress.Array()) > 0 {
+		egress, d := network_policy_helper.CollapseVmPeerRules(ctx, client.Api, network_policy_helper.ReadGqlResultToPeerRules(&jEgress), state.Egress)
+		diags.Append(d...)
+		state.Egress = network_policy_helper.KeepPeerRuleNames(ctx, egress, state.Egress)
+	}
+	return diags
+}
@@ -0,0 +1,48 @@
+package security_policy
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/smartxworks/terraform-provider-everoute/internal/helper/network_policy_helper"
+)
+
+var _ validator.Object = ApplyToValidator{}
+
+func GetApplyToValidator() validator.Object {
+	return ApplyToValidator{}
+}
+
+type ApplyToValidator struct{}
+
+func (v ApplyToValidator) Description(ctx context.Context) string {
+	return v.MarkdownDescription(ctx)
+}
+
+func (v ApplyToValidator) MarkdownDescription(_ context.Context) string {
+	return "Validate security policy apply to, make sure only fields of the apply to type are configured"
+}
+
+func (v ApplyToValidator) ValidateObject(ctx context.Context, req validator.ObjectRequest, resp *validator.ObjectResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	attrs := req.ConfigValue.Attributes()
+	applyType, _ := attrs["type"].(types.String)
+	if applyType.IsUnknown() {
+		// temporary ignore unknown value
+		return
+	}
+	t := network_policy_helper.PeerTypeSelector
+	if !applyType.IsNull() {
+		t = applyType.ValueString()
+	}
+	network_policy_helper.CheckPeerFields(req, resp, t, map[string]bool{
+		"selectors":         network_policy_helper.IsSet(attrs["selectors"]),
+		"security_group_id": network_policy_helper.IsSet(attrs["security_group_id"]),
+	}, map[string][]string{
+		network_policy_helper.PeerTypeSelector:      {"selectors"},
+		network_policy_helper.PeerTypeSecurityGroup: {"security_group_id"},
+	}, nil)
+}